@@ -17,9 +17,13 @@ var (
 
 	outFile   string
 	graphType string
+	format    string
+	export    string
 	byYear    bool
 	byDay     bool
 	compare   bool
+	normalize bool
+	includes  []string
 )
 
 func GetAnalyzeCmd() *cobra.Command {
@@ -32,12 +36,16 @@ func GetAnalyzeCmd() *cobra.Command {
 			RunE:    runAnalyzeCmd,
 		}
 
-		analyzeCmd.Flags().StringVarP(&outFile, "graph", "g", "./run-times.png", "graph output file")
-		analyzeCmd.Flags().StringVarP(&graphType, "type", "t", "line", "type of output graph")
+		analyzeCmd.Flags().StringVarP(&outFile, "graph", "g", "./run-times.png", "graph output file (png or svg)")
+		analyzeCmd.Flags().StringVarP(&graphType, "type", "t", "line", "type of output graph (line, box, heatmap, histogram, bar, scatter)")
+		analyzeCmd.Flags().StringVarP(&format, "format", "f", "", "output format (html, text, markdown, csv)")
+		analyzeCmd.Flags().StringVarP(&export, "export", "e", "", "export every benchmark iteration (csv, jsonl)")
 
 		analyzeCmd.Flags().BoolVarP(&byYear, "year", "y", true, "generate analysis by each year")
 		analyzeCmd.Flags().BoolVarP(&byDay, "day", "d", false, "generate separate analysis for each day")
 		analyzeCmd.Flags().BoolVarP(&compare, "compare", "c", false, "compare run-time metrics")
+		analyzeCmd.Flags().BoolVarP(&normalize, "normalize", "n", false, "normalize run-times across machines")
+		analyzeCmd.Flags().StringSliceVarP(&includes, "include", "i", nil, "additional benchmark files or directories to merge")
 	}
 
 	return analyzeCmd
@@ -58,24 +66,38 @@ func runAnalyzeCmd(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("output file: %w", err)
 	}
 
-	aa, err = advent.NewAnalyzer(cfg, advent.WithDirectory(dir))
+	aa, err = advent.NewAnalyzer(cfg,
+		advent.WithDirectory(dir),
+		advent.WithOutput(outFile),
+		advent.WithNormalize(normalize),
+		advent.WithIncludes(includes),
+		advent.WithYearly(byYear),
+		advent.WithDaily(byDay),
+		advent.WithCompare(compare),
+		advent.WithStatsFormat(advent.StatsFormat(format)),
+		advent.WithExportFormat(advent.ExportFormat(export)),
+		advent.WithTheme(cfg.GetTheme()),
+	)
 	if err != nil {
 		return fmt.Errorf("creating grapher: %w", err)
 	}
 
 	switch {
-	case outFile != "":
-		return aa.Graph(analysis.StringToGraphType(graphType))
+	case export != "":
+		return aa.Export()
 
-	case byYear:
-		return aa.Stats()
+	case format == "html":
+		return aa.Report()
 
-	case byDay:
+	case format != "":
 		return aa.Stats()
 
-	case compare:
+	case cmd.Flags().Changed("year"), cmd.Flags().Changed("day"), cmd.Flags().Changed("compare"):
 		return aa.Stats()
 
+	case outFile != "":
+		return aa.Graph(analysis.StringToGraphType(graphType))
+
 	default:
 		return errors.New("no analysis type")
 	}
@@ -8,18 +8,27 @@ import (
 
 	"github.com/asphaltbuffet/elf/pkg/advent"
 	"github.com/asphaltbuffet/elf/pkg/krampus"
+	"github.com/asphaltbuffet/elf/pkg/runners"
 	"github.com/asphaltbuffet/elf/pkg/tasks"
 )
 
 var (
 	benchmarkCmd *cobra.Command
 	iterations   int
+	languages    []string
+	part         int
+	input        string
+	sweepAll     bool
+	force        bool
 )
 
 const DefaultIterations = 10
 
 const benchmarkExample = `
 elf benchmark --num=5 /path/to/exercise
+elf benchmark --lang=go,py --part=2 /path/to/exercise
+elf benchmark --input-file=alt-input.txt /path/to/exercise
+elf benchmark --all /path/to/year
 elf benchmark /path/to/exercise`
 
 func GetBenchmarkCmd() *cobra.Command {
@@ -34,6 +43,11 @@ func GetBenchmarkCmd() *cobra.Command {
 		}
 
 		benchmarkCmd.Flags().IntVarP(&iterations, "num", "n", DefaultIterations, "number of iterations")
+		benchmarkCmd.Flags().StringSliceVarP(&languages, "lang", "l", nil, "implementations to benchmark (default all)")
+		benchmarkCmd.Flags().IntVarP(&part, "part", "p", 0, "exercise part to benchmark (default both)")
+		benchmarkCmd.Flags().StringVarP(&input, "input-file", "i", "", "override input file")
+		benchmarkCmd.Flags().BoolVarP(&sweepAll, "all", "a", false, "benchmark all exercises in directory")
+		benchmarkCmd.Flags().BoolVarP(&force, "force", "f", false, "benchmark exercises that are up to date (with --all)")
 		benchmarkCmd.Flags().StringP("config-file", "c", "", "configuration file")
 	}
 
@@ -63,7 +77,21 @@ func runBenchmarkCmd(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	ex, err = advent.NewBenchmarker(&cfg, advent.WithExerciseDir(dir))
+	// selection options apply to every exercise when sweeping a directory
+	opts := []func(*advent.Benchmarker){
+		advent.WithImplementations(languages),
+		advent.WithPart(runners.Part(part)), //nolint:gosec // validated by benchmarker
+	}
+
+	if input != "" {
+		opts = append(opts, advent.WithBenchmarkInput(filepath.Clean(input)))
+	}
+
+	if sweepAll {
+		return runSweep(cmd, &cfg, dir, opts)
+	}
+
+	ex, err = advent.NewBenchmarker(&cfg, append(opts, advent.WithExerciseDir(dir))...)
 	if err != nil {
 		return err
 	}
@@ -77,3 +105,23 @@ func runBenchmarkCmd(cmd *cobra.Command, args []string) error {
 	// we don't need to print the error message twice
 	return nil
 }
+
+func runSweep(cmd *cobra.Command, cfg *krampus.Config, dir string, opts []func(*advent.Benchmarker)) error {
+	sweep, err := advent.NewSweep(cfg,
+		advent.WithSweepDir(dir),
+		advent.WithForce(force),
+		advent.WithBenchmarkOptions(opts...))
+	if err != nil {
+		return err
+	}
+
+	results, err := sweep.Run(iterations)
+	if err != nil {
+		cmd.PrintErrln("benchmark failed:", err)
+		return nil
+	}
+
+	advent.WriteSweepSummary(cmd.OutOrStdout(), results)
+
+	return nil
+}
@@ -0,0 +1,112 @@
+package clean
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"github.com/asphaltbuffet/elf/pkg/krampus"
+	"github.com/asphaltbuffet/elf/pkg/runners"
+)
+
+var (
+	cleanCmd   *cobra.Command
+	dryRun     bool
+	force      bool
+	clearCache bool
+)
+
+var errNoForce = errors.New("artifacts are only removed with --force; use --dry-run to list them")
+
+const cleanExample = `  elf clean --dry-run exercises/2015
+  elf clean --force
+  elf clean --force --cache`
+
+func GetCleanCmd() *cobra.Command {
+	if cleanCmd == nil {
+		cleanCmd = &cobra.Command{
+			Use:     "clean [path/to/exercises]",
+			Example: cleanExample,
+			Args:    cobra.MaximumNArgs(1),
+			Short:   "remove runner artifacts left behind by interrupted runs",
+			Long: `Remove wrapper files and build output left in exercise directories, and temporary
+runner workspaces of elf processes that are no longer running.
+
+If no path is given, the configured exercise directory is searched. Nothing is removed
+without --force.`,
+			RunE: runCleanCmd,
+		}
+
+		cleanCmd.Flags().BoolVarP(&dryRun, "dry-run", "n", false, "list artifacts without removing them")
+		cleanCmd.Flags().BoolVarP(&force, "force", "f", false, "remove the artifacts found")
+		cleanCmd.Flags().BoolVar(&clearCache, "cache", false, "also remove cached executables")
+		cleanCmd.Flags().StringP("config-file", "c", "", "configuration file")
+	}
+
+	return cleanCmd
+}
+
+func runCleanCmd(cmd *cobra.Command, args []string) error {
+	if !dryRun && !force {
+		return errNoForce
+	}
+
+	cf, _ := cmd.Flags().GetString("config-file")
+
+	cfg, err := krampus.NewConfig(krampus.WithFile(cf))
+	if err != nil {
+		return err
+	}
+
+	root := cfg.GetBaseDir()
+	if len(args) == 1 {
+		root = args[0]
+	}
+
+	root, err = filepath.Abs(root)
+	if err != nil {
+		return err
+	}
+
+	artifacts, err := runners.FindArtifacts(root)
+	if err != nil {
+		return fmt.Errorf("searching %s: %w", root, err)
+	}
+
+	for _, a := range artifacts {
+		cmd.Println(a)
+	}
+
+	if dryRun {
+		cmd.Printf("found %d artifacts\n", len(artifacts))
+
+		if clearCache {
+			dir, cacheErr := runners.CacheDir()
+			if cacheErr != nil {
+				return fmt.Errorf("finding cache: %w", cacheErr)
+			}
+
+			cmd.Printf("would clear executable cache %s\n", dir)
+		}
+
+		return nil
+	}
+
+	if err = runners.RemoveArtifacts(artifacts); err != nil {
+		return err
+	}
+
+	cmd.Printf("removed %d artifacts\n", len(artifacts))
+
+	if clearCache {
+		if err = runners.ClearCache(); err != nil {
+			return fmt.Errorf("clearing cache: %w", err)
+		}
+
+		cmd.Println("cleared executable cache")
+	}
+
+	return nil
+}
@@ -0,0 +1,31 @@
+package clean_test
+
+import (
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/asphaltbuffet/elf/cmd/clean"
+)
+
+func TestGetCleanCmd(t *testing.T) {
+	t.Run("new command", func(t *testing.T) {
+		assert.NotNil(t, clean.GetCleanCmd())
+	})
+
+	t.Run("existing command", func(t *testing.T) {
+		cmd := clean.GetCleanCmd()
+		assert.Equal(t, cmd, clean.GetCleanCmd())
+	})
+}
+
+func TestCleanCmd_requiresForce(t *testing.T) {
+	cmd := clean.GetCleanCmd()
+	cmd.SetArgs([]string{t.TempDir()})
+	cmd.SetOut(io.Discard)
+	cmd.SetErr(io.Discard)
+
+	require.ErrorContains(t, cmd.Execute(), "--force")
+}
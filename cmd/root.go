@@ -8,11 +8,13 @@ import (
 
 	"github.com/asphaltbuffet/elf/cmd/analyze"
 	"github.com/asphaltbuffet/elf/cmd/benchmark"
+	"github.com/asphaltbuffet/elf/cmd/clean"
 	"github.com/asphaltbuffet/elf/cmd/download"
 	"github.com/asphaltbuffet/elf/cmd/man"
 	"github.com/asphaltbuffet/elf/cmd/solve"
 	"github.com/asphaltbuffet/elf/cmd/test"
 	versionCmd "github.com/asphaltbuffet/elf/cmd/version"
+	"github.com/asphaltbuffet/elf/cmd/visualize"
 	"github.com/asphaltbuffet/elf/pkg/krampus"
 )
 
@@ -50,11 +52,13 @@ func GetRootCommand() *cobra.Command {
 
 		rootCmd.AddCommand(analyze.GetAnalyzeCmd())
 		rootCmd.AddCommand(benchmark.GetBenchmarkCmd())
+		rootCmd.AddCommand(clean.GetCleanCmd())
 		rootCmd.AddCommand(download.GetDownloadCmd())
 		rootCmd.AddCommand(man.NewManCmd())
 		rootCmd.AddCommand(solve.GetSolveCmd())
 		rootCmd.AddCommand(test.GetTestCmd())
 		rootCmd.AddCommand(versionCmd.NewVersionCmd())
+		rootCmd.AddCommand(visualize.GetVisualizeCmd())
 	}
 
 	return rootCmd
@@ -0,0 +1,97 @@
+package visualize
+
+import (
+	"log/slog"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"github.com/asphaltbuffet/elf/pkg/advent"
+	"github.com/asphaltbuffet/elf/pkg/krampus"
+)
+
+var (
+	visualizeCmd *cobra.Command
+	language     string
+	input        string
+	outputDir    string
+)
+
+const exampleText = `
+  elf visualize --lang=go path/to/exercise
+  elf visualize --output=/tmp/frames path/to/exercise
+  elf visualize path/to/exercise # using default language from config`
+
+func GetVisualizeCmd() *cobra.Command {
+	if visualizeCmd == nil {
+		visualizeCmd = &cobra.Command{
+			Use:     "visualize [--lang=<language>] [--output=<dir>] path/to/exercise",
+			Aliases: []string{"vis"},
+			Example: exampleText,
+			Args:    cobra.ExactArgs(1),
+			Short:   "visualize a challenge",
+			Long: `Run the visualization of a challenge with its input.
+
+Output is written to vis/<language> in the exercise directory, which is cleared before each
+run, unless another directory is given with --output.`,
+			RunE: runVisualizeCmd,
+		}
+
+		visualizeCmd.Flags().StringVarP(&language, "lang", "l", "", "solution language")
+		visualizeCmd.Flags().StringVarP(&outputDir, "output", "o", "", "output directory")
+
+		visualizeCmd.Flags().StringP("config-file", "c", "", "configuration file")
+		visualizeCmd.Flags().StringVarP(&input, "input-file", "i", "", "override input file")
+	}
+
+	return visualizeCmd
+}
+
+type Visualizer interface {
+	Visualize(string) ([]string, error)
+	String() string
+}
+
+func runVisualizeCmd(cmd *cobra.Command, args []string) error {
+	var (
+		ch  Visualizer
+		err error
+	)
+
+	cf, _ := cmd.Flags().GetString("config-file")
+
+	cfg, err := krampus.NewConfig(krampus.WithFile(cf))
+	if err != nil {
+		return err
+	}
+
+	dir, err := filepath.Abs(args[0])
+	if err != nil {
+		return err
+	}
+
+	if language == "" {
+		language = cfg.GetLanguage()
+	}
+
+	if input == "" {
+		input = cfg.GetInputFilename()
+	}
+
+	cfg.GetLogger().Debug("visualizing exercise", slog.Group("exercise", "dir", dir, "language", language))
+
+	ch, err = advent.New(&cfg,
+		advent.WithLanguage(language),
+		advent.WithDir(dir),
+		advent.WithInputFile(filepath.Clean(input)))
+	if err != nil {
+		return err
+	}
+
+	_, visErr := ch.Visualize(outputDir)
+	if visErr != nil {
+		cmd.PrintErrln("Failed to visualize: ", visErr)
+	}
+
+	return nil
+}
@@ -0,0 +1,20 @@
+package visualize_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/asphaltbuffet/elf/cmd/visualize"
+)
+
+func TestGetVisualizeCmd(t *testing.T) {
+	t.Run("new command", func(t *testing.T) {
+		assert.NotNil(t, visualize.GetVisualizeCmd())
+	})
+
+	t.Run("existing command", func(t *testing.T) {
+		cmd := visualize.GetVisualizeCmd()
+		assert.Equal(t, cmd, visualize.GetVisualizeCmd())
+	})
+}
@@ -3,7 +3,6 @@ package common
 
 import (
 	"errors"
-	"io"
 )
 
 // BaseExercise is the base struct for all exercises.
@@ -23,9 +22,9 @@ func (e BaseExercise) Two(in string) (any, error) {
 	return nil, errors.New("not implemented")
 }
 
-// Vis is the visualization of the exercise.
+// Vis is the visualization of the exercise. It writes its files to outputDir.
 //
 //nolint:revive // this is a stub
-func (e BaseExercise) Vis(in string, w *io.Writer) error {
+func (e BaseExercise) Vis(in string, outputDir string) error {
 	return errors.New("not implemented")
 }
@@ -2,8 +2,7 @@
 package common
 
 import (
-	"bytes"
-	"io"
+	"os"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -34,11 +33,13 @@ func TestBaseExercise_Two(t *testing.T) {
 
 func TestBaseExercise_Vis(t *testing.T) {
 	t.Parallel()
-	var b bytes.Buffer
 
-	w := io.Writer(&b)
+	dir := t.TempDir()
 	e := BaseExercise{}
 
-	require.Error(t, e.Vis("fake", &w))
-	assert.Empty(t, b.String())
+	require.Error(t, e.Vis("fake", dir))
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Empty(t, entries)
 }
@@ -0,0 +1,72 @@
+package common
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// TaskIDEnv is the environment variable holding the ID of the task being run. The runner
+// wrapper sets it before calling the exercise.
+const TaskIDEnv = "ELF_TASK_ID"
+
+// progressInterval is the least time between progress reports, so reporting from a hot loop
+// does not slow the task down.
+const progressInterval = 100 * time.Millisecond
+
+type progress struct {
+	Done     int64   `json:"done,omitempty"`
+	Total    int64   `json:"total,omitempty"`
+	Fraction float64 `json:"fraction,omitempty"`
+}
+
+var progressState = struct {
+	sync.Mutex
+	w    io.Writer
+	now  func() time.Time
+	last time.Time
+}{
+	w:   os.Stdout,
+	now: time.Now,
+}
+
+// Progress reports that done of total steps of the running task are complete. elf shows it
+// as a progress bar. A total of zero means the number of steps is not known.
+//
+// Reports are dropped if they come too quickly after the previous one, except for the last
+// step.
+func Progress(done, total int) {
+	reportProgress(progress{Done: int64(done), Total: int64(total)}, total > 0 && done >= total)
+}
+
+// ProgressFraction reports the completed fraction of the running task, from 0 to 1.
+//
+// Reports are dropped if they come too quickly after the previous one, except for a
+// completed task.
+func ProgressFraction(fraction float64) {
+	reportProgress(progress{Fraction: fraction}, fraction >= 1)
+}
+
+func reportProgress(p progress, final bool) {
+	progressState.Lock()
+	defer progressState.Unlock()
+
+	now := progressState.now()
+	if !final && now.Sub(progressState.last) < progressInterval {
+		return
+	}
+
+	progressState.last = now
+
+	dat, err := json.Marshal(struct {
+		TaskID   string   `json:"task_id,omitempty"`
+		Progress progress `json:"progress"`
+	}{os.Getenv(TaskIDEnv), p})
+	if err != nil {
+		return
+	}
+
+	_, _ = progressState.w.Write(append(dat, '\n'))
+}
@@ -0,0 +1,44 @@
+package common
+
+import (
+	"bytes"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProgress(t *testing.T) {
+	var (
+		b   bytes.Buffer
+		now = time.Date(2024, 12, 1, 0, 0, 0, 0, time.UTC)
+	)
+
+	progressState.w = &b
+	progressState.now = func() time.Time { return now }
+	progressState.last = time.Time{}
+
+	t.Cleanup(func() {
+		progressState.w = os.Stdout
+		progressState.now = time.Now
+	})
+
+	t.Setenv(TaskIDEnv, "solve.1")
+
+	Progress(1, 10)
+
+	now = now.Add(progressInterval / 2)
+	Progress(2, 10) // too soon
+
+	now = now.Add(progressInterval)
+	ProgressFraction(0.5)
+
+	now = now.Add(time.Millisecond)
+	Progress(10, 10) // the last step is always reported
+
+	assert.Equal(t, `{"task_id":"solve.1","progress":{"done":1,"total":10}}
+{"task_id":"solve.1","progress":{"fraction":0.5}}
+{"task_id":"solve.1","progress":{"done":10,"total":10}}
+`, b.String())
+}
@@ -3,8 +3,12 @@ package main
 
 import (
 	"github.com/asphaltbuffet/elf/cmd"
+	"github.com/asphaltbuffet/elf/pkg/runners"
 )
 
 func main() {
+	// sandboxed runners are started through elf itself, which applies their limits
+	runners.ExecSandboxShim()
+
 	cmd.Execute()
 }
@@ -20,6 +20,51 @@ func (_m *MockAnalyzer) EXPECT() *MockAnalyzer_Expecter {
 	return &MockAnalyzer_Expecter{mock: &_m.Mock}
 }
 
+// Export provides a mock function with given fields:
+func (_m *MockAnalyzer) Export() error {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for Export")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func() error); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockAnalyzer_Export_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Export'
+type MockAnalyzer_Export_Call struct {
+	*mock.Call
+}
+
+// Export is a helper method to define mock.On call
+func (_e *MockAnalyzer_Expecter) Export() *MockAnalyzer_Export_Call {
+	return &MockAnalyzer_Export_Call{Call: _e.mock.On("Export")}
+}
+
+func (_c *MockAnalyzer_Export_Call) Run(run func()) *MockAnalyzer_Export_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *MockAnalyzer_Export_Call) Return(_a0 error) *MockAnalyzer_Export_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockAnalyzer_Export_Call) RunAndReturn(run func() error) *MockAnalyzer_Export_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // Graph provides a mock function with given fields: _a0
 func (_m *MockAnalyzer) Graph(_a0 analysis.GraphType) error {
 	ret := _m.Called(_a0)
@@ -66,6 +111,51 @@ func (_c *MockAnalyzer_Graph_Call) RunAndReturn(run func(analysis.GraphType) err
 	return _c
 }
 
+// Report provides a mock function with given fields:
+func (_m *MockAnalyzer) Report() error {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for Report")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func() error); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockAnalyzer_Report_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Report'
+type MockAnalyzer_Report_Call struct {
+	*mock.Call
+}
+
+// Report is a helper method to define mock.On call
+func (_e *MockAnalyzer_Expecter) Report() *MockAnalyzer_Report_Call {
+	return &MockAnalyzer_Report_Call{Call: _e.mock.On("Report")}
+}
+
+func (_c *MockAnalyzer_Report_Call) Run(run func()) *MockAnalyzer_Report_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *MockAnalyzer_Report_Call) Return(_a0 error) *MockAnalyzer_Report_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockAnalyzer_Report_Call) RunAndReturn(run func() error) *MockAnalyzer_Report_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // Stats provides a mock function with given fields:
 func (_m *MockAnalyzer) Stats() error {
 	ret := _m.Called()
@@ -205,6 +205,51 @@ func (_c *MockDownloadConfiguration_GetFs_Call) RunAndReturn(run func() afero.Fs
 	return _c
 }
 
+// GetGoHelper provides a mock function with given fields:
+func (_m *MockDownloadConfiguration) GetGoHelper() string {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetGoHelper")
+	}
+
+	var r0 string
+	if rf, ok := ret.Get(0).(func() string); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	return r0
+}
+
+// MockDownloadConfiguration_GetGoHelper_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetGoHelper'
+type MockDownloadConfiguration_GetGoHelper_Call struct {
+	*mock.Call
+}
+
+// GetGoHelper is a helper method to define mock.On call
+func (_e *MockDownloadConfiguration_Expecter) GetGoHelper() *MockDownloadConfiguration_GetGoHelper_Call {
+	return &MockDownloadConfiguration_GetGoHelper_Call{Call: _e.mock.On("GetGoHelper")}
+}
+
+func (_c *MockDownloadConfiguration_GetGoHelper_Call) Run(run func()) *MockDownloadConfiguration_GetGoHelper_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *MockDownloadConfiguration_GetGoHelper_Call) Return(_a0 string) *MockDownloadConfiguration_GetGoHelper_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockDownloadConfiguration_GetGoHelper_Call) RunAndReturn(run func() string) *MockDownloadConfiguration_GetGoHelper_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // GetInputFilename provides a mock function with given fields:
 func (_m *MockDownloadConfiguration) GetInputFilename() string {
 	ret := _m.Called()
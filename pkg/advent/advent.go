@@ -8,7 +8,6 @@ import (
 	"os"
 	"path"
 	"path/filepath"
-	"strings"
 
 	"github.com/lmittmann/tint"
 	"github.com/spf13/afero"
@@ -144,10 +143,8 @@ func (e *Exercise) GetImplementations() ([]string, error) {
 			continue
 		}
 
-		name := strings.ToLower(entry.Name())
-
-		if _, ok := runners.Available[name]; ok {
-			impls = append(impls, name)
+		if lang, ok := runners.LanguageForDir(entry.Name()); ok {
+			impls = append(impls, lang)
 		}
 	}
 
@@ -11,6 +11,7 @@ import (
 	"math"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 
 	"github.com/dustin/go-humanize"
@@ -21,7 +22,6 @@ import (
 	"gonum.org/v1/plot/plotutil"
 	"gonum.org/v1/plot/vg"
 	"gonum.org/v1/plot/vg/draw"
-	"gonum.org/v1/plot/vg/vgimg"
 
 	"github.com/asphaltbuffet/elf/pkg/advent"
 	"github.com/asphaltbuffet/elf/pkg/analysis"
@@ -34,21 +34,22 @@ type Analyzer struct {
 	Output    string
 	GraphType analysis.GraphType
 
-	yearly  bool
-	daily   bool
-	compare bool
+	yearly    bool
+	daily     bool
+	compare   bool
+	normalize bool
+	includes  []string
+
+	statsFormat  StatsFormat
+	exportFormat ExportFormat
+	themeCfg     krampus.Theme
+	theme        *plotTheme
 
 	appFs  afero.Fs
 	writer io.Writer
 	logger *slog.Logger
 }
 
-//nolint:mnd // color definition
-var langColor = map[string]color.Color{
-	"Golang": color.RGBA{R: 0, G: 173, B: 216, A: 255},
-	"Python": color.RGBA{R: 55, G: 118, B: 171, A: 255},
-}
-
 func NewAnalyzer(config krampus.ExerciseConfiguration, opts ...func(*Analyzer)) (*Analyzer, error) {
 	analyzer := &Analyzer{
 		appFs:  afero.NewOsFs(),
@@ -64,11 +65,18 @@ func NewAnalyzer(config krampus.ExerciseConfiguration, opts ...func(*Analyzer))
 		return nil, errors.New("no directory specified")
 	}
 
+	theme, err := newPlotTheme(analyzer.themeCfg)
+	if err != nil {
+		return nil, err
+	}
+
+	analyzer.theme = theme
+
 	if analyzer.GraphType == analysis.Invalid {
 		analyzer.GraphType = analysis.Line
 	}
 
-	err := analyzer.Load()
+	err = analyzer.Load()
 	if err != nil {
 		return nil, fmt.Errorf("loading benchmark data: %w", err)
 	}
@@ -94,6 +102,13 @@ func WithOutput(name string) func(*Analyzer) {
 	}
 }
 
+// WithWriter sets where text output, such as statistics tables, is written.
+func WithWriter(w io.Writer) func(*Analyzer) {
+	return func(a *Analyzer) {
+		a.writer = w
+	}
+}
+
 func WithDaily(daily bool) func(*Analyzer) {
 	return func(a *Analyzer) {
 		a.daily = daily
@@ -106,10 +121,39 @@ func WithCompare(compare bool) func(*Analyzer) {
 	}
 }
 
+// WithTheme sets the colors and implementation styles used in graphs.
+func WithTheme(theme krampus.Theme) func(*Analyzer) {
+	return func(a *Analyzer) {
+		a.themeCfg = theme
+	}
+}
+
+// WithNormalize scales running times by the normalization factor of each benchmark run so
+// that runs from different machines can be compared.
+func WithNormalize(normalize bool) func(*Analyzer) {
+	return func(a *Analyzer) {
+		a.normalize = normalize
+	}
+}
+
+// WithIncludes adds benchmark files, or directories containing them, to the analysis. This
+// allows merging benchmark data shared from other machines.
+func WithIncludes(paths []string) func(*Analyzer) {
+	return func(a *Analyzer) {
+		a.includes = paths
+	}
+}
+
 func (a *Analyzer) Load() error {
-	files, err := getBenchmarkFiles(a.Dir)
-	if err != nil {
-		return fmt.Errorf("getting benchmark files: %w", err)
+	var files []string
+
+	for _, dir := range append([]string{a.Dir}, a.includes...) {
+		found, err := getBenchmarkFiles(dir)
+		if err != nil {
+			return fmt.Errorf("getting benchmark files: %w", err)
+		}
+
+		files = append(files, found...)
 	}
 
 	// load benchmark data from files
@@ -117,28 +161,50 @@ func (a *Analyzer) Load() error {
 	benchData := make([]*advent.BenchmarkData, 0, len(files))
 
 	for _, bf := range files {
-		var data []*advent.BenchmarkData
-
-		data, err = readBenchmarkFile(bf)
+		data, err := readBenchmarkFile(bf)
 		if err != nil {
 			return fmt.Errorf("reading %s: %w", bf, err)
 		}
 
+		for _, d := range data {
+			d.Dir = filepath.Dir(bf)
+		}
+
 		benchData = append(benchData, data...)
 	}
 
+	if a.normalize {
+		benchData = normalizeBenchmarks(benchData, a.logger)
+	}
+
 	a.Data = benchData
 
+	if a.theme != nil {
+		a.theme.addRunners(benchData)
+	}
+
 	return nil
 }
 
 func (a *Analyzer) Graph(gt analysis.GraphType) error {
 	switch gt {
 	case analysis.Line:
-		return generateLineGraph(a.Data, a.Output)
+		return generateLineGraph(a.theme, a.Data, a.Output, a.normalize)
 
 	case analysis.Box:
-		return generateBoxPlots(a.Data, a.Output)
+		return generateBoxPlots(a.theme, a.Data, a.Output)
+
+	case analysis.Heatmap:
+		return generateHeatmap(a.theme, a.Data, a.Output)
+
+	case analysis.Histogram:
+		return generateHistograms(a.theme, a.Data, a.Output)
+
+	case analysis.StackedBar:
+		return generateStackedBars(a.theme, a.Data, a.Output)
+
+	case analysis.Scatter:
+		return generateScatter(a.theme, a.Data, a.Output)
 
 	case analysis.Invalid:
 		fallthrough
@@ -148,20 +214,17 @@ func (a *Analyzer) Graph(gt analysis.GraphType) error {
 	}
 }
 
-func (a *Analyzer) Stats() error {
-	return advent.ErrNotImplemented
-}
-
 func getBenchmarkFiles(dir string) ([]string, error) { //nolint:unparam // expected behavior when walking directories
 	benchFiles := []string{}
 
 	// get all benchmark.json files recursively
-	_ = filepath.WalkDir(dir, func(path string, _ fs.DirEntry, err error) error {
+	_ = filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
 		if err != nil {
 			return nil //nolint:nilerr // expected behavior when walking directories
 		}
 
-		if filepath.Base(path) == "benchmark.json" {
+		// a file given directly is used regardless of its name (e.g. shared by a teammate)
+		if filepath.Base(path) == "benchmark.json" || (path == dir && !d.IsDir()) {
 			benchFiles = append(benchFiles, path)
 		}
 
@@ -190,27 +253,131 @@ func readBenchmarkFile(path string) ([]*advent.BenchmarkData, error) {
 	return bd, nil
 }
 
+// normalizeBenchmarks scales the running times of each benchmark run as if all runs were made
+// on the fastest machine in the data set. Runs without a normalization factor can't be
+// compared and are dropped.
+func normalizeBenchmarks(benchmarks []*advent.BenchmarkData, logger *slog.Logger) []*advent.BenchmarkData {
+	ref := math.Inf(1)
+	calibrated := make([]*advent.BenchmarkData, 0, len(benchmarks))
+
+	for _, bd := range benchmarks {
+		attrs := []any{slog.Int("year", bd.Year), slog.Int("day", bd.Day), slog.String("machine", bd.Machine.String())}
+
+		switch {
+		case bd.Normalization <= 0:
+			logger.Warn("skipping benchmark without normalization factor", attrs...)
+
+		case bd.Calibration != advent.CalibrationVersion:
+			// factors from other calibration workloads would skew the comparison
+			logger.Warn("skipping benchmark with outdated normalization factor, rerun the benchmark to include it",
+				append(attrs, slog.Int("calibration", max(bd.Calibration, 1)))...)
+
+		default:
+			ref = min(ref, bd.Normalization)
+			calibrated = append(calibrated, bd)
+		}
+	}
+
+	normalized := make([]*advent.BenchmarkData, 0, len(calibrated))
+
+	for _, bd := range calibrated {
+		scale := ref / bd.Normalization
+
+		nbd := *bd
+		nbd.Implementations = make([]*advent.ImplementationData, 0, len(bd.Implementations))
+
+		for _, impl := range bd.Implementations {
+			nimpl := *impl
+			nimpl.PartOne = scalePartData(impl.PartOne, scale)
+			nimpl.PartTwo = scalePartData(impl.PartTwo, scale)
+
+			nbd.Implementations = append(nbd.Implementations, &nimpl)
+		}
+
+		normalized = append(normalized, &nbd)
+	}
+
+	return normalized
+}
+
+func scalePartData(pd *advent.PartData, scale float64) *advent.PartData {
+	if pd == nil {
+		return nil
+	}
+
+	scaled := &advent.PartData{
+		Mean:     pd.Mean * scale,
+		Min:      pd.Min * scale,
+		Max:      pd.Max * scale,
+		Parse:    pd.Parse * scale,
+		Overhead: pd.Overhead * scale,
+	}
+
+	if pd.Data != nil {
+		scaled.Data = make([]float64, len(pd.Data))
+
+		for i, d := range pd.Data {
+			scaled.Data[i] = d * scale
+		}
+	}
+
+	return scaled
+}
+
+// hasMultipleMachines reports whether the benchmark data was collected on more than one machine.
+func hasMultipleMachines(benchmarks []*advent.BenchmarkData) bool {
+	var machines []string
+
+	for _, bd := range benchmarks {
+		if m := bd.Machine.String(); !slices.Contains(machines, m) {
+			machines = append(machines, m)
+		}
+	}
+
+	return len(machines) > 1
+}
+
+// seriesName returns the name used to group an implementation's data. When data from several
+// machines is analyzed, each machine gets its own series.
+func seriesName(bd *advent.BenchmarkData, impl *advent.ImplementationData, byMachine bool) string {
+	if !byMachine {
+		return impl.Name
+	}
+
+	host := "unknown"
+	if bd.Machine != nil {
+		host = bd.Machine.Host
+	}
+
+	return fmt.Sprintf("%s @ %s", impl.Name, host)
+}
+
 func benchmarkToPlotterXYs(benchmarks []*advent.BenchmarkData) map[string][]plotter.XYs {
 	dataMap := make(map[string][]plotter.XYs)
+	byMachine := hasMultipleMachines(benchmarks)
 
 	for _, bd := range benchmarks {
 		for _, impl := range bd.Implementations {
 			day := float64(bd.Day)
+			name := seriesName(bd, impl, byMachine)
 
-			if _, ok := dataMap[impl.Name]; !ok {
-				dataMap[impl.Name] = make([]plotter.XYs, 2) //nolint:mnd
+			if _, ok := dataMap[name]; !ok {
+				dataMap[name] = make([]plotter.XYs, 2) //nolint:mnd
 			}
 
-			dataMap[impl.Name][0] = append(dataMap[impl.Name][0], plotter.XY{
-				X: day,
-				Y: impl.PartOne.Mean,
-			})
+			// parts may be missing when only one part was benchmarked
+			if impl.PartOne != nil {
+				dataMap[name][0] = append(dataMap[name][0], plotter.XY{
+					X: day,
+					Y: impl.PartOne.Mean,
+				})
+			}
 
 			if impl.PartTwo == nil {
 				continue
 			}
 
-			dataMap[impl.Name][1] = append(dataMap[impl.Name][1],
+			dataMap[name][1] = append(dataMap[name][1],
 				plotter.XY{
 					X: float64(bd.Day),
 					Y: impl.PartTwo.Mean,
@@ -221,10 +388,19 @@ func benchmarkToPlotterXYs(benchmarks []*advent.BenchmarkData) map[string][]plot
 	return dataMap
 }
 
-func generateLineGraph(benchData []*advent.BenchmarkData, outfile string) error {
-	const plotWidthInches font.Length = 12.5 * vg.Inch
-	const plotHeightInches font.Length = 5 * vg.Inch
-	const plotDPI int = 300
+const (
+	lineGraphWidth  font.Length = 12.5 * vg.Inch
+	lineGraphHeight font.Length = 5 * vg.Inch
+)
+
+func generateLineGraph(theme *plotTheme, benchData []*advent.BenchmarkData, outfile string, normalized bool) error {
+	return saveGraph(theme, outfile, lineGraphWidth, lineGraphHeight, func(dc draw.Canvas) error {
+		return drawLineGraph(dc, theme, benchData, normalized)
+	})
+}
+
+// drawLineGraph draws side-by-side line graphs of mean running time per day for each part.
+func drawLineGraph(dc draw.Canvas, theme *plotTheme, benchData []*advent.BenchmarkData, normalized bool) error {
 	const softYMax float64 = 60
 
 	if len(benchData) == 0 {
@@ -236,6 +412,16 @@ func generateLineGraph(benchData []*advent.BenchmarkData, outfile string) error
 		return fmt.Errorf("creating plots: %w", err)
 	}
 
+	for _, p := range plots[0] {
+		theme.apply(p)
+	}
+
+	if normalized {
+		for _, p := range plots[0] {
+			p.Title.Text = strings.Replace(p.Title.Text, "Average", "Normalized Average", 1)
+		}
+	}
+
 	dataMap := benchmarkToPlotterXYs(benchData)
 
 	for lang, parts := range dataMap {
@@ -250,11 +436,22 @@ func generateLineGraph(benchData []*advent.BenchmarkData, outfile string) error
 				return fmt.Errorf("filling %s part %d plot: %w", lang, part, err)
 			}
 
-			ln.Color = langColor[lang]
-			pt.Shape = draw.CircleGlyph{}
-			pt.Color = langColor[lang]
+			style := theme.style(lang)
+
+			ln.Color = style.color
+			ln.Dashes = style.dashes
 
-			plots[0][part].Add(ln, pt)
+			plots[0][part].Add(ln)
+
+			if style.glyph == nil {
+				plots[0][part].Legend.Add(lang, ln)
+				continue
+			}
+
+			pt.Shape = style.glyph
+			pt.Color = style.color
+
+			plots[0][part].Add(pt)
 			plots[0][part].Legend.Add(lang, ln, pt)
 		}
 	}
@@ -268,44 +465,7 @@ func generateLineGraph(benchData []*advent.BenchmarkData, outfile string) error
 	plots[0][0].Y.Min = min
 	plots[0][1].Y.Min = min
 
-	img := vgimg.NewWith(vgimg.UseWH(plotWidthInches, plotHeightInches), vgimg.UseDPI(plotDPI))
-	dc := draw.New(img)
-
-	const rows, cols = 1, 2
-
-	t := draw.Tiles{
-		Rows:      rows,
-		Cols:      cols,
-		PadX:      vg.Points(20),
-		PadRight:  vg.Points(10),
-		PadLeft:   vg.Points(10),
-		PadBottom: vg.Points(10),
-		PadTop:    vg.Points(10),
-	}
-
-	canvases := plot.Align(plots, t, dc)
-
-	for r := range rows {
-		for c := range cols {
-			if plots[r][c] != nil {
-				plots[r][c].Draw(canvases[r][c])
-			}
-		}
-	}
-
-	path, _ := filepath.Abs(outfile)
-	fmt.Printf("writing graph to %s\n", path)
-
-	w, err := os.Create(filepath.Clean(path))
-	if err != nil {
-		return fmt.Errorf("creating image file: %w", err)
-	}
-
-	png := vgimg.PngCanvas{Canvas: img}
-
-	if _, err = png.WriteTo(w); err != nil {
-		return fmt.Errorf("writing image file: %w", err)
-	}
+	drawTiled(dc, plots)
 
 	return nil
 }
@@ -445,7 +605,9 @@ func benchmarkToPlotterValues(benchmarks []*advent.BenchmarkData) map[string]map
 				dataMap[impl.Name][b.Day][1] = plotter.Values{}
 			}
 
-			dataMap[impl.Name][b.Day][0] = append(dataMap[impl.Name][b.Day][0], impl.PartOne.Data...)
+			if impl.PartOne != nil {
+				dataMap[impl.Name][b.Day][0] = append(dataMap[impl.Name][b.Day][0], impl.PartOne.Data...)
+			}
 
 			if impl.PartTwo == nil {
 				continue
@@ -458,7 +620,9 @@ func benchmarkToPlotterValues(benchmarks []*advent.BenchmarkData) map[string]map
 	return dataMap
 }
 
-func generateBoxPlots(benchData []*advent.BenchmarkData, _ string) error {
+// generateBoxPlots draws box plots of running times per day, with one plot for each
+// implementation placed side by side.
+func generateBoxPlots(theme *plotTheme, benchData []*advent.BenchmarkData, outfile string) error {
 	const plotWidthInches font.Length = 4 * vg.Inch
 	const plotHeightInches font.Length = 8 * vg.Inch
 
@@ -469,26 +633,32 @@ func generateBoxPlots(benchData []*advent.BenchmarkData, _ string) error {
 	// pValues is a map of language -> day -> part -> values
 	pValues := benchmarkToPlotterValues(benchData)
 
-	plots, err := makePlotForEachImplementation(benchData[0].Year, pValues)
+	plots, err := makePlotForEachImplementation(theme, benchData[0].Year, pValues)
 	if err != nil {
 		return fmt.Errorf("creating plots: %w", err)
 	}
 
-	for out, p := range plots {
-		if err = p.Save(plotWidthInches, plotHeightInches, out); err != nil {
-			return fmt.Errorf("saving plot: %w", err)
-		}
-	}
+	width := plotWidthInches * font.Length(len(plots))
 
-	return nil
+	return saveGraph(theme, outfile, width, plotHeightInches, func(dc draw.Canvas) error {
+		drawTiled(dc, [][]*plot.Plot{plots})
+		return nil
+	})
 }
 
-func makePlotForEachImplementation(year int, implData ImplDataMap) (map[string]*plot.Plot, error) {
-	// plots maps a filename to a plot
-	plots := make(map[string]*plot.Plot)
+// makePlotForEachImplementation returns a box plot for each implementation, ordered by name.
+func makePlotForEachImplementation(theme *plotTheme, year int, implData ImplDataMap) ([]*plot.Plot, error) {
+	impls := make([]string, 0, len(implData))
+	for impl := range implData {
+		impls = append(impls, impl)
+	}
+
+	slices.Sort(impls)
+
+	plots := make([]*plot.Plot, 0, len(impls))
 
-	for impl, d := range implData {
-		p := plot.New()
+	for _, impl := range impls {
+		p := theme.newPlot()
 
 		p.Title.Text = fmt.Sprintf("Advent of Code %d (%s)", year, impl)
 
@@ -501,12 +671,11 @@ func makePlotForEachImplementation(year int, implData ImplDataMap) (map[string]*
 
 		p.X.Tick.Marker = plot.TickerFunc(dayTicker)
 
-		if err := addDayPartsToPlot(p, d); err != nil {
+		if err := addDayPartsToPlot(p, implData[impl]); err != nil {
 			return nil, err
 		}
 
-		filename := fmt.Sprintf("%s-benchmarks.png", strings.ToLower(impl))
-		plots[filename] = p
+		plots = append(plots, p)
 	}
 
 	return plots, nil
@@ -1,9 +1,15 @@
 package analyze_test
 
 import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
 	"io"
 	"log/slog"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -11,6 +17,8 @@ import (
 	mocks "github.com/asphaltbuffet/elf/mocks/krampus"
 	"github.com/asphaltbuffet/elf/pkg/advent"
 	"github.com/asphaltbuffet/elf/pkg/advent/analyze"
+	"github.com/asphaltbuffet/elf/pkg/analysis"
+	"github.com/asphaltbuffet/elf/pkg/krampus"
 )
 
 func Test_NewAnalyzer(t *testing.T) {
@@ -129,3 +137,526 @@ func Test_NewAnalyzer(t *testing.T) {
 		})
 	}
 }
+
+func Test_NewAnalyzerNormalize(t *testing.T) {
+	dir := t.TempDir()
+	shared := filepath.Join(t.TempDir(), "teammate.json")
+
+	local := []*advent.BenchmarkData{{
+		Year: 2023, Day: 1, Normalization: 0.1, Calibration: advent.CalibrationVersion,
+		Machine: &advent.Machine{Host: "local"},
+		Implementations: []*advent.ImplementationData{
+			{Name: "Go", Runner: "go", PartOne: &advent.PartData{Mean: 1, Min: 1, Max: 1, Data: []float64{1}}},
+		},
+	}}
+	remote := []*advent.BenchmarkData{
+		{
+			Year: 2023, Day: 1, Normalization: 0.2, Calibration: advent.CalibrationVersion,
+			Machine: &advent.Machine{Host: "remote"},
+			Implementations: []*advent.ImplementationData{
+				{Name: "Go", PartOne: &advent.PartData{Mean: 4, Min: 2, Max: 6, Data: []float64{2, 6}}},
+			},
+		},
+		{
+			Year: 2023, Day: 2, Normalization: 0,
+			Implementations: []*advent.ImplementationData{
+				{Name: "Go", PartOne: &advent.PartData{Mean: 4}},
+			},
+		},
+		{
+			// measured with the original calibration workload
+			Year: 2023, Day: 3, Normalization: 0.01,
+			Implementations: []*advent.ImplementationData{
+				{Name: "Go", PartOne: &advent.PartData{Mean: 4}},
+			},
+		},
+	}
+
+	writeJSON := func(path string, v any) {
+		b, err := json.Marshal(v)
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(path, b, 0o600))
+	}
+
+	writeJSON(filepath.Join(dir, "benchmark.json"), local)
+	writeJSON(shared, remote)
+
+	mockConfig := mocks.NewMockExerciseConfiguration(t)
+	mockConfig.EXPECT().GetLogger().Return(slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	got, err := analyze.NewAnalyzer(mockConfig,
+		analyze.WithDirectory(dir),
+		analyze.WithIncludes([]string{shared}),
+		analyze.WithNormalize(true),
+	)
+	require.NoError(t, err)
+
+	// runs without a normalization factor or with an outdated one are dropped
+	require.Len(t, got.Data, 2)
+
+	// runs are scaled to the fastest machine
+	assert.InDelta(t, 1.0, got.Data[0].Implementations[0].PartOne.Mean, 1e-9)
+	assert.InDelta(t, 2.0, got.Data[1].Implementations[0].PartOne.Mean, 1e-9)
+	assert.InDeltaSlice(t, []float64{1, 3}, got.Data[1].Implementations[0].PartOne.Data, 1e-9)
+	assert.Equal(t, "remote", got.Data[1].Machine.Host)
+	assert.Equal(t, "go", got.Data[0].Implementations[0].Runner)
+}
+
+func TestAnalyzer_Report(t *testing.T) {
+	dir := t.TempDir()
+	exDir := filepath.Join(dir, "2023", "01-fakeDay")
+	require.NoError(t, os.MkdirAll(exDir, 0o750))
+	require.NoError(t, os.WriteFile(filepath.Join(exDir, "README.md"), []byte("# Fake"), 0o600))
+
+	data := []*advent.BenchmarkData{{
+		Year: 2023, Day: 1, Title: "Fake Day",
+		Implementations: []*advent.ImplementationData{
+			{Name: "Go", PartOne: &advent.PartData{Mean: 0.001}, PartTwo: &advent.PartData{Mean: 0.002}},
+			{Name: "Python", PartOne: &advent.PartData{Mean: 0.01}},
+		},
+	}}
+
+	b, err := json.Marshal(data)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(exDir, "benchmark.json"), b, 0o600))
+
+	mockConfig := mocks.NewMockExerciseConfiguration(t)
+	mockConfig.EXPECT().GetLogger().Return(slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	a, err := analyze.NewAnalyzer(mockConfig,
+		analyze.WithDirectory(dir),
+		analyze.WithOutput(filepath.Join(dir, "run-times.png")),
+	)
+	require.NoError(t, err)
+
+	require.NoError(t, a.Report())
+
+	got, err := os.ReadFile(filepath.Join(dir, "run-times-2023.html"))
+	require.NoError(t, err)
+
+	report := string(got)
+	assert.Contains(t, report, "Advent of Code 2023 Benchmarks")
+	assert.Contains(t, report, `href="2023/01-fakeDay/README.md"`)
+	assert.Contains(t, report, "Go Part One")
+	assert.Contains(t, report, "Python Part Two")
+	assert.Contains(t, report, "data:image/svg")
+	assert.Contains(t, report, "3 ms") // Go total
+	assert.Contains(t, report, `<td class="text" data-sort="Go">Go</td>`, "every sortable cell should have a sort key")
+}
+
+func TestAnalyzer_Stats(t *testing.T) {
+	dir := t.TempDir()
+
+	data := []*advent.BenchmarkData{
+		{
+			Year: 2023, Day: 1, Title: "Fake Day",
+			Implementations: []*advent.ImplementationData{
+				{
+					Name:    "Go",
+					PartOne: &advent.PartData{Mean: 0.002, Min: 0.001, Max: 0.003, Data: []float64{0.001, 0.002, 0.003}},
+					PartTwo: &advent.PartData{Mean: 0.002},
+				},
+				{Name: "Python", PartOne: &advent.PartData{Mean: 0.01}, PartTwo: &advent.PartData{Mean: 0.001}},
+			},
+		},
+		{
+			Year: 2023, Day: 2, Title: "Fake Day Two",
+			Implementations: []*advent.ImplementationData{
+				{Name: "Go", PartOne: &advent.PartData{Mean: 0.001}},
+				{Name: "Python", PartOne: &advent.PartData{Mean: 0.004}},
+			},
+		},
+	}
+
+	b, err := json.Marshal(data)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "benchmark.json"), b, 0o600))
+
+	tests := []struct {
+		name      string
+		opts      []func(*analyze.Analyzer)
+		want      []string
+		notWant   []string
+		isCSV     bool
+		assertion require.ErrorAssertionFunc
+	}{
+		{
+			name:      "yearly by default",
+			opts:      nil,
+			want:      []string{"Yearly Running Times", "Go", "5 ms", "1.00x", "Python", "15 ms", "3.00x"},
+			notWant:   []string{"Daily Running Times", "Head to Head"},
+			assertion: require.NoError,
+		},
+		{
+			name:      "daily",
+			opts:      []func(*analyze.Analyzer){analyze.WithDaily(true)},
+			want:      []string{"2023: Daily Running Times", "Fake Day Two", "Std Dev", "816.49 µs", "5.00x"},
+			notWant:   []string{"Yearly Running Times"},
+			assertion: require.NoError,
+		},
+		{
+			name: "compare as markdown",
+			opts: []func(*analyze.Analyzer){
+				analyze.WithCompare(true),
+				analyze.WithStatsFormat(analyze.MarkdownFormat),
+			},
+			want: []string{
+				"### Advent of Code 2023: Implementation Ranking",
+				"| 1 | Go | 2 | 1 | 67% |",
+				"| Go | Python | 2 | 1 |",
+			},
+			assertion: require.NoError,
+		},
+		{
+			name:      "csv",
+			opts:      []func(*analyze.Analyzer){analyze.WithStatsFormat(analyze.CSVFormat)},
+			want:      []string{"Table,Year,Implementation,Days,Part One,Part Two,Total,Relative\n", "yearly,2023,Go,2,"},
+			notWant:   []string{"Yearly Running Times", "\n\n"},
+			isCSV:     true,
+			assertion: require.NoError,
+		},
+		{
+			name: "csv with every table",
+			opts: []func(*analyze.Analyzer){
+				analyze.WithDaily(true),
+				analyze.WithYearly(true),
+				analyze.WithCompare(true),
+				analyze.WithStatsFormat(analyze.CSVFormat),
+			},
+			want: []string{
+				"Table,Year,Day,Title,Part,Implementation,Mean,Median,Min,Max,Std Dev,Relative,Rank,Wins,Losses,Win Rate,Opponent,Geo. Mean Ratio,Days,Part One,Part Two,Total\n",
+				"daily,2023,2,Fake Day Two,1,Go,",
+				"ranking,2023,,,,Go,,,,,,,1,2,1,67%,,,,,,\n",
+				"yearly,2023,,,,Go,",
+			},
+			notWant:   []string{"Running Times\n"},
+			isCSV:     true,
+			assertion: require.NoError,
+		},
+		{
+			name:      "invalid format",
+			opts:      []func(*analyze.Analyzer){analyze.WithStatsFormat("yaml")},
+			assertion: require.Error,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockConfig := mocks.NewMockExerciseConfiguration(t)
+			mockConfig.EXPECT().GetLogger().Return(slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+			var buf bytes.Buffer
+
+			opts := append([]func(*analyze.Analyzer){analyze.WithDirectory(dir), analyze.WithWriter(&buf)}, tt.opts...)
+
+			a, err := analyze.NewAnalyzer(mockConfig, opts...)
+			require.NoError(t, err)
+
+			tt.assertion(t, a.Stats())
+
+			for _, w := range tt.want {
+				assert.Contains(t, buf.String(), w)
+			}
+
+			for _, w := range tt.notWant {
+				assert.NotContains(t, buf.String(), w)
+			}
+
+			if tt.isCSV {
+				// every record must have the same number of fields
+				_, err := csv.NewReader(&buf).ReadAll()
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestAnalyzer_Stats_markdownEscapesPipes(t *testing.T) {
+	dir := t.TempDir()
+
+	data := []*advent.BenchmarkData{
+		{
+			Year: 2023, Day: 1, Title: "Fake | Day",
+			Implementations: []*advent.ImplementationData{
+				{Name: "Go|TinyGo", PartOne: &advent.PartData{Mean: 0.001}},
+			},
+		},
+	}
+
+	b, err := json.Marshal(data)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "benchmark.json"), b, 0o600))
+
+	mockConfig := mocks.NewMockExerciseConfiguration(t)
+	mockConfig.EXPECT().GetLogger().Return(slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	var buf bytes.Buffer
+
+	a, err := analyze.NewAnalyzer(mockConfig,
+		analyze.WithDirectory(dir),
+		analyze.WithWriter(&buf),
+		analyze.WithDaily(true),
+		analyze.WithStatsFormat(analyze.MarkdownFormat),
+	)
+	require.NoError(t, err)
+	require.NoError(t, a.Stats())
+
+	assert.Contains(t, buf.String(), `| 1 | Fake \| Day | 1 | Go\|TinyGo |`)
+}
+
+func TestAnalyzer_Graph(t *testing.T) {
+	dir := t.TempDir()
+
+	data := []*advent.BenchmarkData{
+		{
+			Year: 2022, Day: 1, Title: "Fake Day",
+			Implementations: []*advent.ImplementationData{
+				{
+					Name:    "Go",
+					PartOne: &advent.PartData{Mean: 0.002, Data: []float64{0.001, 0.002, 0.003}},
+					PartTwo: &advent.PartData{Mean: 0.004, Data: []float64{0.003, 0.004, 0.005}},
+				},
+			},
+		},
+		{
+			Year: 2023, Day: 2, Title: "Fake Day Two",
+			Implementations: []*advent.ImplementationData{
+				{
+					Name:    "Go",
+					PartOne: &advent.PartData{Mean: 0.001, Data: []float64{0.001, 0.001}},
+					PartTwo: &advent.PartData{Mean: 0.01, Data: []float64{0.009, 0.011}},
+				},
+				{
+					Name:    "Rust",
+					PartOne: &advent.PartData{Mean: 0.0005, Data: []float64{0.0004, 0.0006}},
+					PartTwo: &advent.PartData{Mean: 0.002, Data: []float64{0.002, 0.002}},
+				},
+			},
+		},
+	}
+
+	b, err := json.Marshal(data)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "benchmark.json"), b, 0o600))
+
+	tests := []struct {
+		name      string
+		gt        analysis.GraphType
+		output    string
+		assertion require.ErrorAssertionFunc
+	}{
+		{"line png", analysis.Line, "line.png", require.NoError},
+		{"box svg", analysis.Box, "box.svg", require.NoError},
+		{"heatmap png", analysis.Heatmap, "heatmap.png", require.NoError},
+		{"heatmap svg", analysis.Heatmap, "heatmap.svg", require.NoError},
+		{"histogram svg", analysis.Histogram, "histogram.svg", require.NoError},
+		{"stacked bar png", analysis.StackedBar, "bar.png", require.NoError},
+		{"scatter svg", analysis.Scatter, "scatter.SVG", require.NoError},
+		{"unsupported extension", analysis.Line, "line.jpg", require.Error},
+		{"invalid graph type", analysis.Invalid, "invalid.png", require.Error},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockConfig := mocks.NewMockExerciseConfiguration(t)
+			mockConfig.EXPECT().GetLogger().Return(slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+			out := filepath.Join(t.TempDir(), tt.output)
+
+			a, err := analyze.NewAnalyzer(mockConfig, analyze.WithDirectory(dir), analyze.WithOutput(out))
+			require.NoError(t, err)
+
+			err = a.Graph(tt.gt)
+
+			tt.assertion(t, err)
+			if err == nil {
+				assert.FileExists(t, out)
+			}
+		})
+	}
+}
+
+func Test_NewAnalyzerTheme(t *testing.T) {
+	tests := []struct {
+		name      string
+		theme     krampus.Theme
+		assertion require.ErrorAssertionFunc
+	}{
+		{
+			name:      "default",
+			theme:     krampus.Theme{},
+			assertion: require.NoError,
+		},
+		{
+			name: "dark with styles",
+			theme: krampus.Theme{
+				Preset: krampus.DarkPreset,
+				Implementations: map[string]krampus.ImplementationStyle{
+					"python": {Color: "#ffd43b", Line: "dotted", Marker: "none"},
+					"rust":   {Color: "#dea", Line: "dashed", Marker: "square"},
+				},
+			},
+			assertion: require.NoError,
+		},
+		{
+			name:      "unknown preset",
+			theme:     krampus.Theme{Preset: "sepia"},
+			assertion: require.Error,
+		},
+		{
+			name: "invalid color",
+			theme: krampus.Theme{Implementations: map[string]krampus.ImplementationStyle{
+				"go": {Color: "blue"},
+			}},
+			assertion: require.Error,
+		},
+		{
+			name: "unknown line style",
+			theme: krampus.Theme{Implementations: map[string]krampus.ImplementationStyle{
+				"go": {Line: "wavy"},
+			}},
+			assertion: require.Error,
+		},
+		{
+			name: "unknown marker",
+			theme: krampus.Theme{Implementations: map[string]krampus.ImplementationStyle{
+				"go": {Marker: "star"},
+			}},
+			assertion: require.Error,
+		},
+	}
+
+	dir := t.TempDir()
+
+	data := []*advent.BenchmarkData{{
+		Year: 2023, Day: 1,
+		Implementations: []*advent.ImplementationData{
+			{Name: "Go", PartOne: &advent.PartData{Mean: 0.001}, PartTwo: &advent.PartData{Mean: 0.002}},
+			{Name: "Python", PartOne: &advent.PartData{Mean: 0.01}, PartTwo: &advent.PartData{Mean: 0.02}},
+			{Name: "Rust", PartOne: &advent.PartData{Mean: 0.0005}, PartTwo: &advent.PartData{Mean: 0.001}},
+			{Name: "Zig", PartOne: &advent.PartData{Mean: 0.0004}, PartTwo: &advent.PartData{Mean: 0.001}},
+		},
+	}}
+
+	b, err := json.Marshal(data)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "benchmark.json"), b, 0o600))
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockConfig := mocks.NewMockExerciseConfiguration(t)
+			mockConfig.EXPECT().GetLogger().Return(slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+			out := filepath.Join(t.TempDir(), "line.svg")
+
+			a, err := analyze.NewAnalyzer(mockConfig,
+				analyze.WithDirectory(dir),
+				analyze.WithOutput(out),
+				analyze.WithTheme(tt.theme),
+			)
+
+			tt.assertion(t, err)
+			if err == nil {
+				require.NoError(t, a.Graph(analysis.Line))
+				assert.FileExists(t, out)
+			}
+		})
+	}
+}
+
+func TestAnalyzer_Export(t *testing.T) {
+	dir := t.TempDir()
+	runDate := time.Date(2023, time.December, 2, 12, 0, 0, 0, time.UTC)
+	partDate := time.Date(2023, time.December, 1, 8, 0, 0, 0, time.UTC)
+
+	data := []*advent.BenchmarkData{
+		{
+			Year: 2023, Day: 2, Title: "Second", Date: runDate, Normalization: 0.5, Calibration: advent.CalibrationVersion,
+			Machine: &advent.Machine{Host: "elf", OS: "linux", Arch: "amd64"},
+			Implementations: []*advent.ImplementationData{
+				{Name: "Go", PartOne: &advent.PartData{Mean: 2, Data: []float64{1, 3}}},
+			},
+		},
+		{
+			Year: 2023, Day: 1, Title: "First, Again", Date: runDate,
+			Implementations: []*advent.ImplementationData{
+				{
+					Name:    "Python",
+					PartOne: &advent.PartData{Mean: 4},                                     // no recorded iterations
+					PartTwo: &advent.PartData{Mean: 5, Data: []float64{5}, Date: partDate}, // kept from an earlier run
+				},
+			},
+		},
+	}
+
+	b, err := json.Marshal(data)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "benchmark.json"), b, 0o600))
+
+	tests := []struct {
+		name      string
+		format    analyze.ExportFormat
+		normalize bool
+		want      string
+		assertion require.ErrorAssertionFunc
+	}{
+		{
+			name:   "csv",
+			format: analyze.CSVExport,
+			want: "year,day,title,implementation,part,iteration,seconds,run_date,normalization,normalized,machine\n" +
+				"2023,1,\"First, Again\",Python,2,1,5,2023-12-01T08:00:00Z,0,false,unknown\n" +
+				"2023,2,Second,Go,1,1,1,2023-12-02T12:00:00Z,0.5,false,elf (linux/amd64)\n" +
+				"2023,2,Second,Go,1,2,3,2023-12-02T12:00:00Z,0.5,false,elf (linux/amd64)\n",
+			assertion: require.NoError,
+		},
+		{
+			name:      "csv normalized",
+			format:    analyze.CSVExport,
+			normalize: true,
+			want: "year,day,title,implementation,part,iteration,seconds,run_date,normalization,normalized,machine\n" +
+				"2023,2,Second,Go,1,1,1,2023-12-02T12:00:00Z,0.5,true,elf (linux/amd64)\n" +
+				"2023,2,Second,Go,1,2,3,2023-12-02T12:00:00Z,0.5,true,elf (linux/amd64)\n",
+			assertion: require.NoError,
+		},
+		{
+			name:   "jsonl",
+			format: analyze.JSONLExport,
+			want: `{"year":2023,"day":1,"title":"First, Again","implementation":"Python","part":2,"iteration":1,` +
+				`"seconds":5,"run_date":"2023-12-01T08:00:00Z","normalization":0,"normalized":false,"machine":"unknown"}` + "\n" +
+				`{"year":2023,"day":2,"title":"Second","implementation":"Go","part":1,"iteration":1,` +
+				`"seconds":1,"run_date":"2023-12-02T12:00:00Z","normalization":0.5,"normalized":false,"machine":"elf (linux/amd64)"}` + "\n" +
+				`{"year":2023,"day":2,"title":"Second","implementation":"Go","part":1,"iteration":2,` +
+				`"seconds":3,"run_date":"2023-12-02T12:00:00Z","normalization":0.5,"normalized":false,"machine":"elf (linux/amd64)"}` + "\n",
+			assertion: require.NoError,
+		},
+		{
+			name:      "invalid format",
+			format:    "xlsx",
+			assertion: require.Error,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockConfig := mocks.NewMockExerciseConfiguration(t)
+			mockConfig.EXPECT().GetLogger().Return(slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+			var buf bytes.Buffer
+
+			a, err := analyze.NewAnalyzer(mockConfig,
+				analyze.WithDirectory(dir),
+				analyze.WithWriter(&buf),
+				analyze.WithExportFormat(tt.format),
+				analyze.WithNormalize(tt.normalize),
+			)
+			require.NoError(t, err)
+
+			err = a.Export()
+
+			tt.assertion(t, err)
+			if err == nil {
+				assert.Equal(t, tt.want, buf.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,162 @@
+package analyze
+
+import (
+	"cmp"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/asphaltbuffet/elf/pkg/advent"
+)
+
+// ExportFormat is the file format of exported benchmark data.
+type ExportFormat string
+
+const (
+	CSVExport   ExportFormat = "csv"
+	JSONLExport ExportFormat = "jsonl"
+)
+
+// WithExportFormat sets the format used to export benchmark data.
+func WithExportFormat(f ExportFormat) func(*Analyzer) {
+	return func(a *Analyzer) {
+		a.exportFormat = f
+	}
+}
+
+// exportRow is a single benchmark iteration.
+type exportRow struct {
+	Year           int       `json:"year"`
+	Day            int       `json:"day"`
+	Title          string    `json:"title"`
+	Implementation string    `json:"implementation"`
+	Part           int       `json:"part"`
+	Iteration      int       `json:"iteration"`
+	Seconds        float64   `json:"seconds"`
+	RunDate        time.Time `json:"run_date"`
+	Normalization  float64   `json:"normalization"`
+	Normalized     bool      `json:"normalized"`
+	Machine        string    `json:"machine"`
+}
+
+var exportHeaders = []string{
+	"year", "day", "title", "implementation", "part", "iteration", "seconds", "run_date", "normalization", "normalized", "machine",
+}
+
+func (r exportRow) record() []string {
+	return []string{
+		strconv.Itoa(r.Year),
+		strconv.Itoa(r.Day),
+		r.Title,
+		r.Implementation,
+		strconv.Itoa(r.Part),
+		strconv.Itoa(r.Iteration),
+		strconv.FormatFloat(r.Seconds, 'g', -1, 64),
+		r.RunDate.Format(time.RFC3339),
+		strconv.FormatFloat(r.Normalization, 'g', -1, 64),
+		strconv.FormatBool(r.Normalized),
+		r.Machine,
+	}
+}
+
+// Export writes every stored benchmark run to the analyzer output, with one row for each
+// iteration of each part. Parts without recorded iterations are skipped.
+//
+// When normalizing, seconds are scaled to the fastest machine and the rows are marked as
+// normalized; the normalization column keeps the factor of the machine that ran them.
+func (a *Analyzer) Export() error {
+	if len(a.Data) == 0 {
+		return errors.New("no benchmark data to export")
+	}
+
+	rows := exportRows(a.Data, a.normalize)
+
+	switch a.exportFormat {
+	case CSVExport:
+		w := csv.NewWriter(a.writer)
+
+		if err := w.Write(exportHeaders); err != nil {
+			return fmt.Errorf("writing csv: %w", err)
+		}
+
+		for _, r := range rows {
+			if err := w.Write(r.record()); err != nil {
+				return fmt.Errorf("writing csv: %w", err)
+			}
+		}
+
+		w.Flush()
+
+		if err := w.Error(); err != nil {
+			return fmt.Errorf("writing csv: %w", err)
+		}
+
+	case JSONLExport:
+		enc := json.NewEncoder(a.writer)
+
+		for _, r := range rows {
+			if err := enc.Encode(r); err != nil {
+				return fmt.Errorf("writing jsonl: %w", err)
+			}
+		}
+
+	default:
+		return fmt.Errorf("%w: %s", ErrInvalidFormat, a.exportFormat)
+	}
+
+	return nil
+}
+
+// exportRows flattens benchmark runs into iterations, ordered by exercise and run date. Parts
+// kept from an earlier run are dated when they were benchmarked.
+func exportRows(data []*advent.BenchmarkData, normalized bool) []exportRow {
+	runs := slices.Clone(data)
+
+	slices.SortStableFunc(runs, func(x, y *advent.BenchmarkData) int {
+		return cmp.Or(
+			cmp.Compare(x.Year, y.Year),
+			cmp.Compare(x.Day, y.Day),
+			x.Date.Compare(y.Date),
+		)
+	})
+
+	var rows []exportRow
+
+	for _, bd := range runs {
+		for _, impl := range bd.Implementations {
+			for part := range 2 {
+				pd := partData(impl, part)
+				if pd == nil {
+					continue
+				}
+
+				runDate := pd.Date
+				if runDate.IsZero() {
+					runDate = bd.Date
+				}
+
+				for i, d := range pd.Data {
+					rows = append(rows, exportRow{
+						Year:           bd.Year,
+						Day:            bd.Day,
+						Title:          bd.Title,
+						Implementation: impl.Name,
+						Part:           part + 1,
+						Iteration:      i + 1,
+						Seconds:        d,
+						RunDate:        runDate,
+						Normalization:  bd.Normalization,
+						Normalized:     normalized,
+						Machine:        bd.Machine.String(),
+					})
+				}
+			}
+		}
+	}
+
+	return rows
+}
@@ -0,0 +1,644 @@
+package analyze
+
+import (
+	"errors"
+	"fmt"
+	"image/color"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/dustin/go-humanize"
+	"gonum.org/v1/plot"
+	"gonum.org/v1/plot/font"
+	"gonum.org/v1/plot/palette/moreland"
+	"gonum.org/v1/plot/plotter"
+	"gonum.org/v1/plot/plotutil"
+	"gonum.org/v1/plot/vg"
+	"gonum.org/v1/plot/vg/draw"
+	"gonum.org/v1/plot/vg/vgimg"
+	"gonum.org/v1/plot/vg/vgsvg"
+
+	"github.com/asphaltbuffet/elf/pkg/advent"
+)
+
+var ErrUnsupportedImage = errors.New("unsupported image format")
+
+// saveGraph draws a graph on a canvas of the given size, filled with the theme background, and
+// writes it to outfile. The image format (PNG or SVG) is chosen by the file extension.
+func saveGraph(theme *plotTheme, outfile string, width, height vg.Length, drawGraph func(draw.Canvas) error) error {
+	const plotDPI int = 300
+
+	var c vg.CanvasWriterTo
+
+	switch ext := strings.ToLower(filepath.Ext(outfile)); ext {
+	case ".png":
+		c = vgimg.PngCanvas{Canvas: vgimg.NewWith(vgimg.UseWH(width, height), vgimg.UseDPI(plotDPI))}
+	case ".svg":
+		c = vgsvg.New(width, height)
+	default:
+		return fmt.Errorf("%w: %q", ErrUnsupportedImage, ext)
+	}
+
+	dc := draw.New(c)
+	dc.SetColor(theme.background)
+	dc.Fill(dc.Rectangle.Path())
+
+	if err := drawGraph(dc); err != nil {
+		return err
+	}
+
+	path, _ := filepath.Abs(outfile)
+	fmt.Printf("writing graph to %s\n", path)
+
+	w, err := os.Create(filepath.Clean(path))
+	if err != nil {
+		return fmt.Errorf("creating image file: %w", err)
+	}
+	defer w.Close()
+
+	if _, err = c.WriteTo(w); err != nil {
+		return fmt.Errorf("writing image file: %w", err)
+	}
+
+	return nil
+}
+
+// drawTiled draws a grid of plots, aligning their axes.
+func drawTiled(dc draw.Canvas, plots [][]*plot.Plot) {
+	if len(plots) == 0 || len(plots[0]) == 0 {
+		return
+	}
+
+	t := draw.Tiles{
+		Rows:      len(plots),
+		Cols:      len(plots[0]),
+		PadX:      vg.Points(20),
+		PadY:      vg.Points(20),
+		PadRight:  vg.Points(10),
+		PadLeft:   vg.Points(10),
+		PadBottom: vg.Points(10),
+		PadTop:    vg.Points(10),
+	}
+
+	canvases := plot.Align(plots, t, dc)
+
+	for r := range plots {
+		for c := range plots[r] {
+			if plots[r][c] != nil {
+				plots[r][c].Draw(canvases[r][c])
+			}
+		}
+	}
+}
+
+// implNames returns the names of all implementations in the benchmark data, ordered by name.
+func implNames(benchData []*advent.BenchmarkData) []string {
+	var impls []string
+
+	for _, bd := range benchData {
+		for _, impl := range bd.Implementations {
+			if !slices.Contains(impls, impl.Name) {
+				impls = append(impls, impl.Name)
+			}
+		}
+	}
+
+	slices.Sort(impls)
+
+	return impls
+}
+
+// sortedYears returns the latest benchmark run of each day, grouped by year in order.
+func sortedYears(benchData []*advent.BenchmarkData) ([]int, map[int][]*advent.BenchmarkData) {
+	byYear := groupByYear(benchData)
+
+	years := make([]int, 0, len(byYear))
+
+	for y, data := range byYear {
+		years = append(years, y)
+		byYear[y] = latestByDay(data)
+	}
+
+	slices.Sort(years)
+
+	return years, byYear
+}
+
+// totalMean returns the combined mean running time of all parts of an implementation,
+// including parsing the input.
+func totalMean(impl *advent.ImplementationData) float64 {
+	total := parseMean(impl)
+
+	for _, p := range []*advent.PartData{impl.PartOne, impl.PartTwo} {
+		if p != nil {
+			total += p.Mean
+		}
+	}
+
+	return total
+}
+
+// parseMean returns the mean time an implementation spent parsing its input. The parts share
+// the parsed input, so it is only counted once.
+func parseMean(impl *advent.ImplementationData) float64 {
+	for _, p := range []*advent.PartData{impl.PartOne, impl.PartTwo} {
+		if p != nil && p.Parse > 0 {
+			return p.Parse
+		}
+	}
+
+	return 0
+}
+
+// humanizedLogLabels labels integer ticks of an axis holding log10 running times.
+type humanizedLogLabels struct{}
+
+var _ plot.Ticker = humanizedLogLabels{}
+
+// Ticks returns a labelled tick for each power of ten in the range.
+func (humanizedLogLabels) Ticks(min, max float64) []plot.Tick {
+	var ticks []plot.Tick
+
+	for v := math.Ceil(min); v <= max; v++ {
+		ticks = append(ticks, plot.Tick{Value: v, Label: humanize.SIWithDigits(math.Pow10(int(v)), 0, "s")})
+	}
+
+	return ticks
+}
+
+// humanizedTicks labels the default ticks of a linear axis with humanized running times.
+type humanizedTicks struct{}
+
+var _ plot.Ticker = humanizedTicks{}
+
+// Ticks returns the default ticks for the range with humanized labels.
+func (humanizedTicks) Ticks(min, max float64) []plot.Tick {
+	ticks := plot.DefaultTicks{}.Ticks(min, max)
+
+	for i := range ticks {
+		if ticks[i].Label != "" {
+			ticks[i].Label = humanize.SIWithDigits(ticks[i].Value, 2, "s") //nolint:mnd // display precision
+		}
+	}
+
+	return ticks
+}
+
+// heatGrid holds the log10 total running time of each implementation (row) for each day
+// (column). Missing data is NaN.
+type heatGrid struct {
+	days  []int
+	impls []string
+	z     [][]float64
+}
+
+var _ plotter.GridXYZ = (*heatGrid)(nil)
+
+func (g *heatGrid) Dims() (int, int)   { return len(g.days), len(g.impls) }
+func (g *heatGrid) Z(c, r int) float64 { return g.z[r][c] }
+func (g *heatGrid) X(c int) float64    { return float64(g.days[c]) }
+func (g *heatGrid) Y(r int) float64    { return float64(r) }
+
+// implTicks labels each row of a heatmap with its implementation.
+func implTicks(impls []string) []plot.Tick {
+	ticks := make([]plot.Tick, 0, len(impls))
+
+	for i, impl := range impls {
+		ticks = append(ticks, plot.Tick{Value: float64(i), Label: impl})
+	}
+
+	return ticks
+}
+
+// dayTicks labels each column of a heatmap with its day.
+func dayTicks(days []int) []plot.Tick {
+	ticks := make([]plot.Tick, 0, len(days))
+
+	for _, day := range days {
+		ticks = append(ticks, plot.Tick{Value: float64(day), Label: strconv.Itoa(day)})
+	}
+
+	return ticks
+}
+
+// newHeatGrid returns a grid with a column for each day up to the last day in the data.
+func newHeatGrid(data []*advent.BenchmarkData, impls []string) *heatGrid {
+	g := &heatGrid{impls: impls}
+
+	for day := 1; day <= data[len(data)-1].Day; day++ {
+		g.days = append(g.days, day)
+	}
+
+	g.z = make([][]float64, len(impls))
+	for r := range g.z {
+		g.z[r] = make([]float64, len(g.days))
+
+		for c := range g.z[r] {
+			g.z[r][c] = math.NaN()
+		}
+	}
+
+	for _, bd := range data {
+		if bd.Day < 1 || bd.Day > len(g.days) {
+			continue
+		}
+
+		for _, impl := range bd.Implementations {
+			if t := totalMean(impl); t > 0 {
+				g.z[slices.Index(impls, impl.Name)][bd.Day-1] = math.Log10(t)
+			}
+		}
+	}
+
+	return g
+}
+
+// generateHeatmap draws a heatmap of total running time per day and implementation for each
+// year, colored on a log scale.
+func generateHeatmap(theme *plotTheme, benchData []*advent.BenchmarkData, outfile string) error {
+	const (
+		rowHeight   font.Length = 0.5 * vg.Inch
+		yearPadding font.Length = 1.5 * vg.Inch
+		width       font.Length = 12.5 * vg.Inch
+		barWidth    font.Length = 1.25 * vg.Inch
+		paletteSize             = 255
+	)
+
+	if len(benchData) == 0 {
+		return errors.New("no benchmark data to graph")
+	}
+
+	impls := implNames(benchData)
+	years, byYear := sortedYears(benchData)
+
+	grids := make([]*heatGrid, 0, len(years))
+	zMin, zMax := math.Inf(1), math.Inf(-1)
+
+	for _, year := range years {
+		g := newHeatGrid(byYear[year], impls)
+
+		for _, row := range g.z {
+			for _, z := range row {
+				if !math.IsNaN(z) {
+					zMin, zMax = min(zMin, z), max(zMax, z)
+				}
+			}
+		}
+
+		grids = append(grids, g)
+	}
+
+	if math.IsInf(zMin, 0) {
+		return errors.New("no running times to graph")
+	}
+
+	// the color scale needs a non-empty range
+	if zMin == zMax {
+		zMin, zMax = zMin-0.5, zMax+0.5 //nolint:mnd // half a decade either side
+	}
+
+	cm := moreland.Kindlmann()
+	cm.SetMin(zMin)
+	cm.SetMax(zMax)
+
+	plots := make([][]*plot.Plot, 0, len(years))
+
+	for i, year := range years {
+		hm := plotter.NewHeatMap(grids[i], cm.Palette(paletteSize))
+		hm.Min, hm.Max = zMin, zMax
+		hm.NaN = color.Transparent
+
+		p := theme.newPlot()
+		p.Title.Text = fmt.Sprintf("Total Running Time\nAdvent of Code %d", year)
+		p.X.Label.Text = "Day"
+		p.X.Tick.Marker = plot.ConstantTicks(dayTicks(grids[i].days))
+		p.Y.Tick.Marker = plot.ConstantTicks(implTicks(impls))
+		p.Add(hm)
+
+		plots = append(plots, []*plot.Plot{p})
+	}
+
+	bar := theme.newPlot()
+	bar.Title.Text = "Running Time"
+	bar.HideX()
+	bar.Y.Min, bar.Y.Max = zMin, zMax
+	bar.Y.Tick.Marker = humanizedLogLabels{}
+	bar.Add(&plotter.ColorBar{ColorMap: cm, Vertical: true})
+
+	height := (rowHeight*font.Length(len(impls)) + yearPadding) * font.Length(len(years))
+
+	return saveGraph(theme, outfile, width, height, func(dc draw.Canvas) error {
+		maps := draw.Crop(dc, 0, -barWidth, 0, 0)
+		legend := draw.Crop(dc, dc.Max.X-dc.Min.X-barWidth, 0, 0, 0)
+
+		drawTiled(maps, plots)
+		drawTiled(legend, [][]*plot.Plot{{bar}})
+
+		return nil
+	})
+}
+
+// generateHistograms draws the distribution of iteration running times for each
+// implementation, with one plot for each implementation placed side by side. Running times
+// are binned on a log scale so that fast and slow days share a plot.
+func generateHistograms(theme *plotTheme, benchData []*advent.BenchmarkData, outfile string) error {
+	const (
+		plotWidth  font.Length = 5 * vg.Inch
+		plotHeight font.Length = 4 * vg.Inch
+		numBins                = 40
+		numParts               = 2
+	)
+
+	if len(benchData) == 0 {
+		return errors.New("no benchmark data to graph")
+	}
+
+	//nolint:mnd // color definition
+	partColors := []color.Color{
+		color.NRGBA{R: 0, G: 173, B: 216, A: 160},
+		color.NRGBA{R: 222, G: 100, B: 90, A: 160},
+	}
+
+	years, byYear := sortedYears(benchData)
+
+	var plots []*plot.Plot
+
+	for _, impl := range implNames(benchData) {
+		// values holds log10 iteration times for each part
+		values := make([]plotter.Values, numParts)
+
+		for _, year := range years {
+			for _, bd := range byYear[year] {
+				idx := slices.IndexFunc(bd.Implementations, func(i *advent.ImplementationData) bool { return i.Name == impl })
+				if idx == -1 {
+					continue
+				}
+
+				for part := range numParts {
+					pd := partData(bd.Implementations[idx], part)
+					if pd == nil {
+						continue
+					}
+
+					for _, d := range pd.Data {
+						if d > 0 {
+							values[part] = append(values[part], math.Log10(d))
+						}
+					}
+				}
+			}
+		}
+
+		if len(values[0])+len(values[1]) == 0 {
+			continue
+		}
+
+		p := theme.newPlot()
+		p.Title.Text = fmt.Sprintf("Iteration Running Times (%s)", impl)
+		p.X.Label.Text = "Running time"
+		p.X.Tick.Marker = humanizedLogLabels{}
+		p.Y.Label.Text = "Iterations"
+
+		for part, v := range values {
+			if len(v) == 0 {
+				continue
+			}
+
+			h, err := plotter.NewHist(v, numBins)
+			if err != nil {
+				return fmt.Errorf("creating %s histogram: %w", impl, err)
+			}
+
+			h.FillColor = partColors[part]
+			h.LineStyle.Width = 0
+
+			p.Add(h)
+			p.Legend.Add(fmt.Sprintf("Part %d", part+1), h)
+		}
+
+		p.Legend.Top = true
+
+		plots = append(plots, p)
+	}
+
+	if len(plots) == 0 {
+		return errors.New("no iteration data to graph")
+	}
+
+	return saveGraph(theme, outfile, plotWidth*font.Length(len(plots)), plotHeight, func(dc draw.Canvas) error {
+		drawTiled(dc, [][]*plot.Plot{plots})
+		return nil
+	})
+}
+
+// generateStackedBars draws the total running time of each implementation for each year. Each
+// bar is split into the time taken by part one and part two, on top of the time spent parsing
+// the input for implementations with a parse step.
+func generateStackedBars(theme *plotTheme, benchData []*advent.BenchmarkData, outfile string) error {
+	const (
+		barWidth   font.Length = 0.4 * vg.Inch
+		plotHeight font.Length = 5 * vg.Inch
+		minWidth   font.Length = 8 * vg.Inch
+		groupPad   font.Length = 1 * vg.Inch
+		fadedAlpha             = 0.5
+		parseAlpha             = 0.25
+	)
+
+	if len(benchData) == 0 {
+		return errors.New("no benchmark data to graph")
+	}
+
+	impls := implNames(benchData)
+	years, byYear := sortedYears(benchData)
+
+	p := theme.newPlot()
+	p.Title.Text = "Total Running Time by Year"
+	p.Y.Label.Text = "Running time"
+	p.Y.Tick.Marker = humanizedTicks{}
+	p.Legend.Top = true
+
+	for i, impl := range impls {
+		parse := make(plotter.Values, len(years))
+		one := make(plotter.Values, len(years))
+		two := make(plotter.Values, len(years))
+		hasParse := false
+
+		for y, year := range years {
+			for _, bd := range byYear[year] {
+				idx := slices.IndexFunc(bd.Implementations, func(i *advent.ImplementationData) bool { return i.Name == impl })
+				if idx == -1 {
+					continue
+				}
+
+				if pm := parseMean(bd.Implementations[idx]); pm > 0 {
+					parse[y] += pm
+					hasParse = true
+				}
+
+				if pd := bd.Implementations[idx].PartOne; pd != nil {
+					one[y] += pd.Mean
+				}
+
+				if pd := bd.Implementations[idx].PartTwo; pd != nil {
+					two[y] += pd.Mean
+				}
+			}
+		}
+
+		offset := barWidth * font.Length(float64(i)-float64(len(impls)-1)/2) //nolint:mnd // center the group
+
+		c := theme.style(impl).color
+
+		b1, err := plotter.NewBarChart(one, barWidth)
+		if err != nil {
+			return fmt.Errorf("creating %s bars: %w", impl, err)
+		}
+
+		b1.Color = c
+		b1.Offset = offset
+		b1.LineStyle.Width = 0
+
+		if hasParse {
+			b0, err := plotter.NewBarChart(parse, barWidth)
+			if err != nil {
+				return fmt.Errorf("creating %s bars: %w", impl, err)
+			}
+
+			b0.Color = fade(c, parseAlpha)
+			b0.Offset = offset
+			b0.LineStyle.Width = 0
+			b1.StackOn(b0)
+
+			p.Add(b0)
+			p.Legend.Add(impl+" Parse", b0)
+		}
+
+		b2, err := plotter.NewBarChart(two, barWidth)
+		if err != nil {
+			return fmt.Errorf("creating %s bars: %w", impl, err)
+		}
+
+		b2.Color = fade(c, fadedAlpha)
+		b2.Offset = offset
+		b2.LineStyle.Width = 0
+		b2.StackOn(b1)
+
+		p.Add(b1, b2)
+		p.Legend.Add(impl+" Part One", b1)
+		p.Legend.Add(impl+" Part Two", b2)
+	}
+
+	yearNames := make([]string, 0, len(years))
+	for _, year := range years {
+		yearNames = append(yearNames, strconv.Itoa(year))
+	}
+
+	p.NominalX(yearNames...)
+
+	width := max(minWidth, (barWidth*font.Length(len(impls))+groupPad)*font.Length(len(years)))
+
+	return saveGraph(theme, outfile, width, plotHeight, func(dc draw.Canvas) error {
+		p.Draw(dc)
+		return nil
+	})
+}
+
+// fade returns the color with its opacity scaled by alpha.
+func fade(c color.Color, alpha float64) color.Color {
+	n, _ := color.NRGBA64Model.Convert(c).(color.NRGBA64)
+	n.A = uint16(float64(n.A) * alpha)
+
+	return n
+}
+
+// generateScatter plots the part one running time of each exercise against its part two
+// running time. Points above the diagonal took longer to solve part two.
+func generateScatter(theme *plotTheme, benchData []*advent.BenchmarkData, outfile string) error {
+	const (
+		size       font.Length = 7 * vg.Inch
+		glyphWidth             = 3
+	)
+
+	if len(benchData) == 0 {
+		return errors.New("no benchmark data to graph")
+	}
+
+	years, byYear := sortedYears(benchData)
+
+	p := theme.newPlot()
+	p.Title.Text = "Part One vs Part Two Running Time"
+	p.X.Label.Text = "Part one"
+	p.Y.Label.Text = "Part two"
+	p.X.Scale, p.Y.Scale = plot.LogScale{}, plot.LogScale{}
+	p.X.Tick.Marker, p.Y.Tick.Marker = HumanizedLogTicks{}, HumanizedLogTicks{}
+	p.Legend.Top = true
+	p.Legend.Left = true
+
+	lo, hi := math.Inf(1), math.Inf(-1)
+
+	for _, impl := range implNames(benchData) {
+		var xys plotter.XYs
+
+		for _, year := range years {
+			for _, bd := range byYear[year] {
+				idx := slices.IndexFunc(bd.Implementations, func(i *advent.ImplementationData) bool { return i.Name == impl })
+				if idx == -1 {
+					continue
+				}
+
+				one, two := bd.Implementations[idx].PartOne, bd.Implementations[idx].PartTwo
+				if one == nil || two == nil || one.Mean <= 0 || two.Mean <= 0 {
+					continue
+				}
+
+				xys = append(xys, plotter.XY{X: one.Mean, Y: two.Mean})
+				lo, hi = min(lo, one.Mean, two.Mean), max(hi, one.Mean, two.Mean)
+			}
+		}
+
+		if len(xys) == 0 {
+			continue
+		}
+
+		s, err := plotter.NewScatter(xys)
+		if err != nil {
+			return fmt.Errorf("creating %s scatter: %w", impl, err)
+		}
+
+		style := theme.style(impl)
+
+		// a scatter plot needs markers, even if the implementation has none configured
+		s.Shape = draw.CircleGlyph{}
+		if style.glyph != nil {
+			s.Shape = style.glyph
+		}
+
+		s.Color = style.color
+		s.Radius = vg.Points(glyphWidth)
+
+		p.Add(s)
+		p.Legend.Add(impl, s)
+	}
+
+	if math.IsInf(lo, 0) {
+		return errors.New("no exercises with both parts to graph")
+	}
+
+	// both axes share a range so the diagonal is at 45 degrees
+	p.X.Min, p.X.Max = lo, hi
+	p.Y.Min, p.Y.Max = lo, hi
+
+	diagonal := plotter.NewFunction(func(x float64) float64 { return x })
+	diagonal.Color = color.Gray{Y: 128}  //nolint:mnd // color definition
+	diagonal.Dashes = plotutil.Dashes(2) //nolint:mnd // dash pattern
+	p.Add(diagonal)
+
+	return saveGraph(theme, outfile, size, size, func(dc draw.Canvas) error {
+		p.Draw(dc)
+		return nil
+	})
+}
@@ -0,0 +1,291 @@
+package analyze
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"html/template"
+	"math"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/dustin/go-humanize"
+	"github.com/spf13/afero"
+	"gonum.org/v1/plot/vg/draw"
+	"gonum.org/v1/plot/vg/vgsvg"
+
+	"github.com/asphaltbuffet/elf/pkg/advent"
+)
+
+//go:embed templates/report.tmpl
+var reportTemplate string
+
+type reportData struct {
+	Year       int
+	Generated  time.Time
+	Normalized bool
+	Chart      template.URL
+	Impls      []string
+	Days       []reportDay
+	Extremes   []reportExtreme
+}
+
+type reportDay struct {
+	Day    int
+	Title  string
+	Readme string
+
+	// Times holds part one and part two times for each implementation, in order.
+	Times []reportTime
+}
+
+type reportTime struct {
+	Value   float64
+	Label   string
+	Missing bool
+}
+
+type reportExtreme struct {
+	Impl        string
+	Fastest     reportDay
+	FastestTime reportTime
+	Slowest     reportDay
+	SlowestTime reportTime
+	Total       reportTime
+}
+
+// Report writes a self-contained HTML report for each year of benchmark data. Reports are
+// written next to the graph output file, named after it with the year appended.
+func (a *Analyzer) Report() error {
+	if len(a.Data) == 0 {
+		return errors.New("no benchmark data to report")
+	}
+
+	tmpl, err := template.New("report").Parse(reportTemplate)
+	if err != nil {
+		return fmt.Errorf("parsing report template: %w", err)
+	}
+
+	for year, data := range groupByYear(a.Data) {
+		outfile := reportFilename(a.Output, year)
+
+		rd, err := a.buildReport(year, data, filepath.Dir(outfile))
+		if err != nil {
+			return fmt.Errorf("building %d report: %w", year, err)
+		}
+
+		buf := new(bytes.Buffer)
+
+		if err = tmpl.Execute(buf, rd); err != nil {
+			return fmt.Errorf("rendering %d report: %w", year, err)
+		}
+
+		path, _ := filepath.Abs(outfile)
+		fmt.Fprintf(a.writer, "writing report to %s\n", path)
+
+		if err = afero.WriteFile(a.appFs, outfile, buf.Bytes(), 0o600); err != nil {
+			return fmt.Errorf("writing report file: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// reportFilename returns the report file for a year, based on the output file name.
+//
+// Example: "out/run-times.png" => "out/run-times-2023.html".
+func reportFilename(output string, year int) string {
+	if output == "" {
+		output = "report"
+	}
+
+	base := strings.TrimSuffix(output, filepath.Ext(output))
+
+	return fmt.Sprintf("%s-%d.html", base, year)
+}
+
+func groupByYear(data []*advent.BenchmarkData) map[int][]*advent.BenchmarkData {
+	years := make(map[int][]*advent.BenchmarkData)
+
+	for _, bd := range data {
+		years[bd.Year] = append(years[bd.Year], bd)
+	}
+
+	return years
+}
+
+// latestByDay returns the most recent benchmark run for each day, ordered by day.
+func latestByDay(data []*advent.BenchmarkData) []*advent.BenchmarkData {
+	days := make(map[int]*advent.BenchmarkData)
+
+	for _, bd := range data {
+		if prev, ok := days[bd.Day]; !ok || bd.Date.After(prev.Date) {
+			days[bd.Day] = bd
+		}
+	}
+
+	latest := make([]*advent.BenchmarkData, 0, len(days))
+	for _, bd := range days {
+		latest = append(latest, bd)
+	}
+
+	slices.SortFunc(latest, func(x, y *advent.BenchmarkData) int { return x.Day - y.Day })
+
+	return latest
+}
+
+func (a *Analyzer) buildReport(year int, data []*advent.BenchmarkData, reportDir string) (*reportData, error) {
+	chart, err := svgDataURI(a.theme, data, a.normalize)
+	if err != nil {
+		return nil, err
+	}
+
+	rd := &reportData{
+		Year:       year,
+		Generated:  time.Now(),
+		Normalized: a.normalize,
+		Chart:      chart,
+	}
+
+	days := latestByDay(data)
+
+	for _, bd := range days {
+		for _, impl := range bd.Implementations {
+			if !slices.Contains(rd.Impls, impl.Name) {
+				rd.Impls = append(rd.Impls, impl.Name)
+			}
+		}
+	}
+
+	slices.Sort(rd.Impls)
+
+	extremes := make(map[string]*reportExtreme, len(rd.Impls))
+
+	for _, bd := range days {
+		day := reportDay{
+			Day:    bd.Day,
+			Title:  bd.Title,
+			Readme: a.readmeLink(bd.Dir, reportDir),
+		}
+
+		for _, name := range rd.Impls {
+			idx := slices.IndexFunc(bd.Implementations, func(i *advent.ImplementationData) bool { return i.Name == name })
+			if idx == -1 {
+				day.Times = append(day.Times, reportTime{Missing: true}, reportTime{Missing: true})
+				continue
+			}
+
+			impl := bd.Implementations[idx]
+			day.Times = append(day.Times, newReportTime(impl.PartOne), newReportTime(impl.PartTwo))
+		}
+
+		for i, name := range rd.Impls {
+			one, two := day.Times[2*i], day.Times[2*i+1]
+			if one.Missing && two.Missing {
+				continue
+			}
+
+			updateExtremes(extremes, name, day, one.Value+two.Value)
+		}
+
+		rd.Days = append(rd.Days, day)
+	}
+
+	for _, name := range rd.Impls {
+		if e, ok := extremes[name]; ok {
+			rd.Extremes = append(rd.Extremes, *e)
+		}
+	}
+
+	return rd, nil
+}
+
+func updateExtremes(extremes map[string]*reportExtreme, name string, day reportDay, total float64) {
+	e, ok := extremes[name]
+	if !ok {
+		e = &reportExtreme{
+			Impl:        name,
+			FastestTime: reportTime{Value: math.Inf(1)},
+			SlowestTime: reportTime{Value: math.Inf(-1)},
+		}
+		extremes[name] = e
+	}
+
+	if total < e.FastestTime.Value {
+		e.Fastest, e.FastestTime = day, newTime(total)
+	}
+
+	if total > e.SlowestTime.Value {
+		e.Slowest, e.SlowestTime = day, newTime(total)
+	}
+
+	e.Total = newTime(e.Total.Value + total)
+}
+
+func newReportTime(pd *advent.PartData) reportTime {
+	if pd == nil {
+		return reportTime{Missing: true}
+	}
+
+	return newTime(pd.Mean)
+}
+
+func newTime(seconds float64) reportTime {
+	return reportTime{
+		Value: seconds,
+		Label: humanize.SIWithDigits(seconds, 2, "s"), //nolint:mnd // display precision
+	}
+}
+
+// readmeLink returns the path to the exercise README relative to the report, or an empty
+// string if the exercise has no README.
+func (a *Analyzer) readmeLink(exerciseDir, reportDir string) string {
+	if exerciseDir == "" {
+		return ""
+	}
+
+	readme := filepath.Join(exerciseDir, "README.md")
+
+	if ok, _ := afero.Exists(a.appFs, readme); !ok {
+		return ""
+	}
+
+	absReport, err := filepath.Abs(reportDir)
+	if err != nil {
+		return ""
+	}
+
+	absReadme, err := filepath.Abs(readme)
+	if err != nil {
+		return ""
+	}
+
+	rel, err := filepath.Rel(absReport, absReadme)
+	if err != nil {
+		return ""
+	}
+
+	return filepath.ToSlash(rel)
+}
+
+// svgDataURI renders the line graph as an SVG image embedded in a data URI.
+func svgDataURI(theme *plotTheme, data []*advent.BenchmarkData, normalized bool) (template.URL, error) {
+	svg := vgsvg.New(lineGraphWidth, lineGraphHeight)
+
+	if err := drawLineGraph(draw.New(svg), theme, data, normalized); err != nil {
+		return "", err
+	}
+
+	buf := new(bytes.Buffer)
+
+	if _, err := svg.WriteTo(buf); err != nil {
+		return "", fmt.Errorf("rendering svg: %w", err)
+	}
+
+	//nolint:gosec // content is generated by gonum/plot
+	return template.URL("data:image/svg+xml;base64," + base64.StdEncoding.EncodeToString(buf.Bytes())), nil
+}
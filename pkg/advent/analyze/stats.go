@@ -0,0 +1,536 @@
+package analyze
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/lipgloss/table"
+	"github.com/dustin/go-humanize"
+	"github.com/montanaflynn/stats"
+
+	"github.com/asphaltbuffet/elf/pkg/advent"
+)
+
+// StatsFormat is the output format of statistics tables.
+type StatsFormat string
+
+const (
+	TextFormat     StatsFormat = "text"
+	MarkdownFormat StatsFormat = "markdown"
+	CSVFormat      StatsFormat = "csv"
+)
+
+var ErrInvalidFormat = errors.New("invalid format")
+
+// WithStatsFormat sets the output format for statistics. The default is text.
+func WithStatsFormat(f StatsFormat) func(*Analyzer) {
+	return func(a *Analyzer) {
+		a.statsFormat = f
+	}
+}
+
+// partStats holds summary statistics for the running times of one exercise part.
+type partStats struct {
+	Mean   float64
+	Median float64
+	Min    float64
+	Max    float64
+	StdDev float64
+}
+
+// implPartStats ties part statistics to the implementation that produced them.
+type implPartStats struct {
+	Impl  string
+	Stats partStats
+}
+
+// newPartStats calculates statistics for a part. Benchmark files without raw data only have
+// their stored mean, min, and max; median and standard deviation are NaN in that case.
+func newPartStats(pd *advent.PartData) (partStats, bool) {
+	if pd == nil {
+		return partStats{}, false
+	}
+
+	ps := partStats{
+		Mean:   pd.Mean,
+		Median: math.NaN(),
+		Min:    pd.Min,
+		Max:    pd.Max,
+		StdDev: math.NaN(),
+	}
+
+	if len(pd.Data) == 0 {
+		return ps, true
+	}
+
+	data := stats.LoadRawData(pd.Data)
+
+	// errors only occur for empty data, which is handled above
+	ps.Mean, _ = data.Mean()
+	ps.Median, _ = data.Median()
+	ps.Min, _ = data.Min()
+	ps.Max, _ = data.Max()
+	ps.StdDev, _ = data.StandardDeviation()
+
+	return ps, true
+}
+
+func partData(impl *advent.ImplementationData, part int) *advent.PartData {
+	if part == 0 {
+		return impl.PartOne
+	}
+
+	return impl.PartTwo
+}
+
+// Stats writes tables of running time statistics to the analyzer output.
+//
+// Yearly totals are shown by default. Daily statistics and head-to-head comparisons of
+// implementations are added with the daily and compare options.
+func (a *Analyzer) Stats() error {
+	if len(a.Data) == 0 {
+		return errors.New("no benchmark data to analyze")
+	}
+
+	tw, err := newTableWriter(a.writer, a.statsFormat)
+	if err != nil {
+		return err
+	}
+
+	yearly := a.yearly || (!a.daily && !a.compare)
+
+	byYear := groupByYear(a.Data)
+
+	years := make([]int, 0, len(byYear))
+	for y := range byYear {
+		years = append(years, y)
+	}
+
+	slices.Sort(years)
+
+	var totals [][]string
+
+	for _, year := range years {
+		days := latestByDay(byYear[year])
+
+		if a.daily {
+			tw.write(statsTable{
+				name:    "daily",
+				title:   fmt.Sprintf("Advent of Code %d: Daily Running Times", year),
+				year:    year,
+				headers: dailyHeaders,
+				rows:    dailyRows(days),
+			})
+		}
+
+		if yearly {
+			totals = append(totals, yearlyRows(year, days)...)
+		}
+
+		if a.compare {
+			ranking, pairs := compareRows(days)
+
+			tw.write(statsTable{
+				name:    "ranking",
+				title:   fmt.Sprintf("Advent of Code %d: Implementation Ranking", year),
+				year:    year,
+				headers: rankingHeaders,
+				rows:    ranking,
+			})
+			tw.write(statsTable{
+				name:    "head-to-head",
+				title:   fmt.Sprintf("Advent of Code %d: Head to Head", year),
+				year:    year,
+				headers: headToHeadHeaders,
+				rows:    pairs,
+			})
+		}
+	}
+
+	if yearly {
+		tw.write(statsTable{name: "yearly", title: "Yearly Running Times", headers: yearlyHeaders, rows: totals})
+	}
+
+	return tw.flush()
+}
+
+var dailyHeaders = []string{
+	"Day", "Title", "Part", "Implementation", "Mean", "Median", "Min", "Max", "Std Dev", "Relative",
+}
+
+// dailyRows returns statistics for each implementation and part of each day. Rows for a part
+// are ordered fastest first, with running times relative to the fastest implementation.
+func dailyRows(days []*advent.BenchmarkData) [][]string {
+	var rows [][]string
+
+	for _, bd := range days {
+		for part := range 2 {
+			var parts []implPartStats
+
+			for _, impl := range bd.Implementations {
+				if ps, ok := newPartStats(partData(impl, part)); ok {
+					parts = append(parts, implPartStats{Impl: impl.Name, Stats: ps})
+				}
+			}
+
+			slices.SortFunc(parts, func(x, y implPartStats) int { return cmpFloat(x.Stats.Mean, y.Stats.Mean) })
+
+			for _, p := range parts {
+				rows = append(rows, []string{
+					strconv.Itoa(bd.Day),
+					bd.Title,
+					strconv.Itoa(part + 1),
+					p.Impl,
+					formatSeconds(p.Stats.Mean),
+					formatSeconds(p.Stats.Median),
+					formatSeconds(p.Stats.Min),
+					formatSeconds(p.Stats.Max),
+					formatSeconds(p.Stats.StdDev),
+					formatRatio(p.Stats.Mean, parts[0].Stats.Mean),
+				})
+			}
+		}
+	}
+
+	return rows
+}
+
+var yearlyHeaders = []string{"Year", "Implementation", "Days", "Part One", "Part Two", "Total", "Relative"}
+
+// yearlyRows returns the total of mean running times for each implementation in a year,
+// ordered fastest first.
+func yearlyRows(year int, days []*advent.BenchmarkData) [][]string {
+	type total struct {
+		impl     string
+		days     int
+		one, two float64
+	}
+
+	var totals []*total
+
+	for _, bd := range days {
+		for _, impl := range bd.Implementations {
+			idx := slices.IndexFunc(totals, func(t *total) bool { return t.impl == impl.Name })
+			if idx == -1 {
+				totals = append(totals, &total{impl: impl.Name})
+				idx = len(totals) - 1
+			}
+
+			t := totals[idx]
+			t.days++
+
+			if impl.PartOne != nil {
+				t.one += impl.PartOne.Mean
+			}
+
+			if impl.PartTwo != nil {
+				t.two += impl.PartTwo.Mean
+			}
+		}
+	}
+
+	slices.SortFunc(totals, func(x, y *total) int { return cmpFloat(x.one+x.two, y.one+y.two) })
+
+	rows := make([][]string, 0, len(totals))
+
+	for _, t := range totals {
+		rows = append(rows, []string{
+			strconv.Itoa(year),
+			t.impl,
+			strconv.Itoa(t.days),
+			formatSeconds(t.one),
+			formatSeconds(t.two),
+			formatSeconds(t.one + t.two),
+			formatRatio(t.one+t.two, totals[0].one+totals[0].two),
+		})
+	}
+
+	return rows
+}
+
+var (
+	rankingHeaders    = []string{"Rank", "Implementation", "Wins", "Losses", "Win Rate"}
+	headToHeadHeaders = []string{"Implementation", "Opponent", "Wins", "Losses", "Geo. Mean Ratio"}
+)
+
+// compareRows compares every pair of implementations on each part they both solved. It
+// returns a ranking by head-to-head wins and the results of each pairing. The geometric
+// mean ratio is the typical running time of an implementation relative to its opponent.
+func compareRows(days []*advent.BenchmarkData) ([][]string, [][]string) {
+	type record struct {
+		wins, losses int
+		logRatios    []float64
+	}
+
+	var impls []string
+
+	for _, bd := range days {
+		for _, impl := range bd.Implementations {
+			if !slices.Contains(impls, impl.Name) {
+				impls = append(impls, impl.Name)
+			}
+		}
+	}
+
+	slices.Sort(impls)
+
+	// records maps implementation -> opponent -> record
+	records := make(map[string]map[string]*record, len(impls))
+	for _, impl := range impls {
+		records[impl] = make(map[string]*record, len(impls))
+		for _, opp := range impls {
+			records[impl][opp] = &record{}
+		}
+	}
+
+	for _, bd := range days {
+		for part := range 2 {
+			for _, x := range bd.Implementations {
+				for _, y := range bd.Implementations {
+					px, py := partData(x, part), partData(y, part)
+					if x.Name == y.Name || px == nil || py == nil || px.Mean <= 0 || py.Mean <= 0 {
+						continue
+					}
+
+					r := records[x.Name][y.Name]
+					r.logRatios = append(r.logRatios, math.Log(px.Mean/py.Mean))
+
+					if px.Mean < py.Mean {
+						r.wins++
+					} else if px.Mean > py.Mean {
+						r.losses++
+					}
+				}
+			}
+		}
+	}
+
+	type rank struct {
+		impl         string
+		wins, losses int
+	}
+
+	ranks := make([]rank, 0, len(impls))
+
+	var pairs [][]string
+
+	for _, impl := range impls {
+		rk := rank{impl: impl}
+
+		for _, opp := range impls {
+			r := records[impl][opp]
+			if impl == opp || len(r.logRatios) == 0 {
+				continue
+			}
+
+			rk.wins += r.wins
+			rk.losses += r.losses
+
+			mean, _ := stats.Mean(r.logRatios)
+
+			pairs = append(pairs, []string{
+				impl,
+				opp,
+				strconv.Itoa(r.wins),
+				strconv.Itoa(r.losses),
+				fmt.Sprintf("%.2fx", math.Exp(mean)),
+			})
+		}
+
+		ranks = append(ranks, rk)
+	}
+
+	slices.SortStableFunc(ranks, func(x, y rank) int {
+		return cmpFloat(winRate(y.wins, y.losses), winRate(x.wins, x.losses))
+	})
+
+	ranking := make([][]string, 0, len(ranks))
+
+	for i, rk := range ranks {
+		ranking = append(ranking, []string{
+			strconv.Itoa(i + 1),
+			rk.impl,
+			strconv.Itoa(rk.wins),
+			strconv.Itoa(rk.losses),
+			fmt.Sprintf("%.0f%%", 100*winRate(rk.wins, rk.losses)), //nolint:mnd // percentage
+		})
+	}
+
+	return ranking, pairs
+}
+
+func winRate(wins, losses int) float64 {
+	if wins+losses == 0 {
+		return 0
+	}
+
+	return float64(wins) / float64(wins+losses)
+}
+
+func cmpFloat(x, y float64) int {
+	switch {
+	case x < y:
+		return -1
+	case x > y:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func formatSeconds(s float64) string {
+	if math.IsNaN(s) {
+		return "-"
+	}
+
+	return humanize.SIWithDigits(s, 2, "s") //nolint:mnd // display precision
+}
+
+func formatRatio(x, fastest float64) string {
+	if fastest <= 0 {
+		return "-"
+	}
+
+	return fmt.Sprintf("%.2fx", x/fastest)
+}
+
+// statsTable is a titled table of statistics.
+type statsTable struct {
+	name    string // identifies the table in csv records
+	title   string
+	year    int // zero when the table has its own year column
+	headers []string
+	rows    [][]string
+}
+
+// tableWriter writes titled tables in one of the supported statistics formats.
+//
+// CSV has no notion of separate tables, so csv output is a single table: rows are held until
+// flush and written under one header with every column of the tables, led by the name of the
+// table and the year of the row. Cells a table doesn't have are left empty.
+type tableWriter struct {
+	w      io.Writer
+	format StatsFormat
+	tables []statsTable
+	err    error
+	count  int
+}
+
+func newTableWriter(w io.Writer, format StatsFormat) (*tableWriter, error) {
+	tw := &tableWriter{w: w, format: format}
+
+	switch format {
+	case "", TextFormat:
+		tw.format = TextFormat
+	case MarkdownFormat, "md":
+		tw.format = MarkdownFormat
+	case CSVFormat:
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrInvalidFormat, format)
+	}
+
+	return tw, nil
+}
+
+func (tw *tableWriter) write(t statsTable) {
+	if tw.err != nil {
+		return
+	}
+
+	if tw.format == CSVFormat {
+		tw.tables = append(tw.tables, t)
+		return
+	}
+
+	// separate tables with a blank line
+	if tw.count > 0 {
+		tw.printf("\n")
+	}
+
+	tw.count++
+
+	switch tw.format {
+	case MarkdownFormat:
+		tw.printf("### %s\n\n", t.title)
+		tw.printf("%s\n", markdownRow(t.headers))
+		tw.printf("|%s\n", strings.Repeat(" --- |", len(t.headers)))
+
+		for _, row := range t.rows {
+			tw.printf("%s\n", markdownRow(row))
+		}
+
+	case TextFormat:
+		tbl := table.New().
+			Border(lipgloss.NormalBorder()).
+			Headers(t.headers...).
+			Rows(t.rows...)
+
+		tw.printf("%s\n%s\n", lipgloss.NewStyle().Bold(true).Render(t.title), tbl)
+	}
+}
+
+// markdownRow formats cells as a markdown table row. Pipes in cells are escaped so names
+// containing them don't split the cell.
+func markdownRow(cells []string) string {
+	escaped := make([]string, len(cells))
+	for i, c := range cells {
+		escaped[i] = strings.ReplaceAll(strings.ReplaceAll(c, "|", `\|`), "\n", " ")
+	}
+
+	return "| " + strings.Join(escaped, " | ") + " |"
+}
+
+func (tw *tableWriter) printf(format string, args ...any) {
+	if tw.err != nil {
+		return
+	}
+
+	_, tw.err = fmt.Fprintf(tw.w, format, args...)
+}
+
+func (tw *tableWriter) flush() error {
+	if tw.err != nil || tw.format != CSVFormat {
+		return tw.err
+	}
+
+	headers := []string{"Table", "Year"}
+
+	for _, t := range tw.tables {
+		for _, h := range t.headers {
+			if !slices.Contains(headers, h) {
+				headers = append(headers, h)
+			}
+		}
+	}
+
+	w := csv.NewWriter(tw.w)
+	w.Write(headers) //nolint:errcheck // checked below
+
+	for _, t := range tw.tables {
+		for _, row := range t.rows {
+			record := make([]string, len(headers))
+			record[0] = t.name
+
+			if t.year != 0 {
+				record[1] = strconv.Itoa(t.year)
+			}
+
+			for i, h := range t.headers {
+				record[slices.Index(headers, h)] = row[i]
+			}
+
+			w.Write(record) //nolint:errcheck // checked below
+		}
+	}
+
+	w.Flush()
+
+	return w.Error()
+}
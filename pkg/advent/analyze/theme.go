@@ -0,0 +1,253 @@
+package analyze
+
+import (
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"image/color"
+	"strconv"
+	"strings"
+
+	"gonum.org/v1/plot"
+	"gonum.org/v1/plot/plotutil"
+	"gonum.org/v1/plot/vg"
+	"gonum.org/v1/plot/vg/draw"
+
+	"github.com/asphaltbuffet/elf/pkg/advent"
+	"github.com/asphaltbuffet/elf/pkg/krampus"
+)
+
+var ErrInvalidTheme = errors.New("invalid theme")
+
+// plotTheme holds the colors used to draw graphs and the style of each implementation.
+type plotTheme struct {
+	background color.Color
+	foreground color.Color
+
+	// palette is used for implementations without a configured or default color
+	palette []color.Color
+
+	// impls maps a lowercase implementation name or runner key to its configured style
+	impls map[string]implStyle
+
+	// runners maps a lowercase implementation name to the key of the runner it was run with
+	runners map[string]string
+}
+
+// implStyle is how an implementation's data is drawn.
+type implStyle struct {
+	color  color.Color
+	dashes []vg.Length
+
+	// glyph is nil when no markers are drawn
+	glyph draw.GlyphDrawer
+}
+
+// defaultImplColors are the colors of implementations without a configured color, keyed by
+// the language key of the runner. Variants have their own keys, so they get palette colors
+// that tell them apart from their base implementation.
+//
+//nolint:mnd // color definition
+var defaultImplColors = map[string]color.Color{
+	"go":   color.RGBA{R: 0, G: 173, B: 216, A: 255},
+	"py":   color.RGBA{R: 55, G: 118, B: 171, A: 255},
+	"js":   color.RGBA{R: 240, G: 219, B: 79, A: 255},
+	"ts":   color.RGBA{R: 49, G: 120, B: 198, A: 255},
+	"c":    color.RGBA{R: 168, G: 185, B: 204, A: 255},
+	"cpp":  color.RGBA{R: 243, G: 75, B: 125, A: 255},
+	"wasm": color.RGBA{R: 101, G: 79, B: 240, A: 255},
+}
+
+// builtinRunnerKeys maps the lowercase names of the built-in runners to their keys, for
+// benchmark data recorded before the runner key was stored.
+var builtinRunnerKeys = map[string]string{
+	"go":          "go",
+	"python":      "py",
+	"javascript":  "js",
+	"typescript":  "ts",
+	"c":           "c",
+	"c++":         "cpp",
+	"webassembly": "wasm",
+}
+
+//nolint:mnd // color definition
+var (
+	lightTheme = plotTheme{
+		background: color.White,
+		foreground: color.Black,
+		palette:    plotutil.DarkColors,
+	}
+
+	darkTheme = plotTheme{
+		background: color.Gray{Y: 24},
+		foreground: color.Gray{Y: 232},
+		palette:    plotutil.SoftColors,
+	}
+)
+
+// newPlotTheme returns the plot theme for a theme configuration.
+func newPlotTheme(cfg krampus.Theme) (*plotTheme, error) {
+	var theme plotTheme
+
+	switch strings.ToLower(cfg.Preset) {
+	case "", krampus.LightPreset:
+		theme = lightTheme
+	case krampus.DarkPreset:
+		theme = darkTheme
+	default:
+		return nil, fmt.Errorf("%w: unknown preset %q", ErrInvalidTheme, cfg.Preset)
+	}
+
+	theme.impls = make(map[string]implStyle, len(cfg.Implementations))
+	theme.runners = map[string]string{}
+
+	for name, is := range cfg.Implementations {
+		style, err := newImplStyle(is)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s: %w", ErrInvalidTheme, name, err)
+		}
+
+		theme.impls[strings.ToLower(name)] = style
+	}
+
+	return &theme, nil
+}
+
+func newImplStyle(is krampus.ImplementationStyle) (implStyle, error) {
+	var (
+		style implStyle
+		err   error
+	)
+
+	if is.Color != "" {
+		if style.color, err = parseHexColor(is.Color); err != nil {
+			return implStyle{}, err
+		}
+	}
+
+	switch strings.ToLower(is.Line) {
+	case "", "solid":
+	case "dashed":
+		style.dashes = []vg.Length{vg.Points(6), vg.Points(3)} //nolint:mnd // dash pattern
+	case "dotted":
+		style.dashes = []vg.Length{vg.Points(1), vg.Points(2)} //nolint:mnd // dash pattern
+	default:
+		return implStyle{}, fmt.Errorf("unknown line style %q", is.Line)
+	}
+
+	switch strings.ToLower(is.Marker) {
+	case "", "circle":
+		style.glyph = draw.CircleGlyph{}
+	case "ring":
+		style.glyph = draw.RingGlyph{}
+	case "square":
+		style.glyph = draw.SquareGlyph{}
+	case "box":
+		style.glyph = draw.BoxGlyph{}
+	case "triangle":
+		style.glyph = draw.TriangleGlyph{}
+	case "cross":
+		style.glyph = draw.CrossGlyph{}
+	case "plus":
+		style.glyph = draw.PlusGlyph{}
+	case "none":
+	default:
+		return implStyle{}, fmt.Errorf("unknown marker %q", is.Marker)
+	}
+
+	return style, nil
+}
+
+// parseHexColor parses colors in the form "#rgb" or "#rrggbb".
+func parseHexColor(s string) (color.Color, error) {
+	hex := strings.TrimPrefix(s, "#")
+
+	if len(hex) == 3 { //nolint:mnd // short form
+		hex = string([]byte{hex[0], hex[0], hex[1], hex[1], hex[2], hex[2]})
+	}
+
+	v, err := strconv.ParseUint(hex, 16, 32)
+	if len(hex) != 6 || err != nil {
+		return nil, fmt.Errorf("invalid color %q", s)
+	}
+
+	//nolint:gosec,mnd // each channel is masked to 8 bits
+	return color.RGBA{R: uint8(v >> 16), G: uint8(v >> 8), B: uint8(v), A: 255}, nil
+}
+
+// addRunners records the runner each implementation in the benchmark data was run with, so
+// implementations are styled by runner rather than by name.
+func (t *plotTheme) addRunners(benchData []*advent.BenchmarkData) {
+	for _, bd := range benchData {
+		for _, impl := range bd.Implementations {
+			if impl != nil && impl.Runner != "" {
+				t.runners[strings.ToLower(impl.Name)] = strings.ToLower(impl.Runner)
+			}
+		}
+	}
+}
+
+// runnerKey returns the key of the runner an implementation was run with, if known.
+func (t *plotTheme) runnerKey(name string) string {
+	if key, ok := t.runners[name]; ok {
+		return key
+	}
+
+	return builtinRunnerKeys[name]
+}
+
+// style returns the style for a data series. The series may be an implementation name or an
+// implementation on a specific machine (e.g. "Go @ host").
+//
+// Styles are configured by implementation name or runner key. Implementations without a
+// configured color use the default color of their runner, or a color from the theme palette
+// chosen by name so it is the same in every graph.
+func (t *plotTheme) style(series string) implStyle {
+	name, _, _ := strings.Cut(series, " @ ")
+	name = strings.ToLower(name)
+	key := t.runnerKey(name)
+
+	style, ok := t.impls[name]
+	if !ok {
+		style, ok = t.impls[key]
+	}
+
+	if !ok {
+		style.glyph = draw.CircleGlyph{}
+	}
+
+	if style.color == nil {
+		style.color = defaultImplColors[key]
+	}
+
+	if style.color == nil {
+		h := fnv.New32a()
+		h.Write([]byte(name))
+
+		style.color = t.palette[h.Sum32()%uint32(len(t.palette))] //nolint:gosec // palette is small
+	}
+
+	return style
+}
+
+// newPlot returns an empty plot drawn in the theme colors.
+func (t *plotTheme) newPlot() *plot.Plot {
+	p := plot.New()
+	t.apply(p)
+
+	return p
+}
+
+// apply draws the plot in the theme colors.
+func (t *plotTheme) apply(p *plot.Plot) {
+	p.BackgroundColor = t.background
+	p.Title.TextStyle.Color = t.foreground
+	p.Legend.TextStyle.Color = t.foreground
+
+	for _, axis := range []*plot.Axis{&p.X, &p.Y} {
+		axis.Color = t.foreground
+		axis.Label.TextStyle.Color = t.foreground
+		axis.Tick.Color = t.foreground
+		axis.Tick.Label.Color = t.foreground
+	}
+}
@@ -0,0 +1,52 @@
+package analyze
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/asphaltbuffet/elf/pkg/advent"
+	"github.com/asphaltbuffet/elf/pkg/krampus"
+)
+
+func Test_plotTheme_style(t *testing.T) {
+	theme, err := newPlotTheme(krampus.Theme{Implementations: map[string]krampus.ImplementationStyle{
+		"cpp": {Color: "#123456"},
+	}})
+	require.NoError(t, err)
+
+	theme.addRunners([]*advent.BenchmarkData{{
+		Implementations: []*advent.ImplementationData{
+			{Name: "Python", Runner: "py"},
+			{Name: "Python (PyPy)", Runner: "pypy"},
+			{Name: "Clang", Runner: "cpp"},
+		},
+	}})
+
+	tests := []struct {
+		name   string
+		series string
+		want   string
+	}{
+		{"runner default", "Python", "py"},
+		{"runner default on another machine", "Python @ host", "py"},
+		{"built-in name without runner", "Go", "go"},
+		{"configured by runner key", "Clang", "configured"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			want := defaultImplColors[tt.want]
+			if tt.want == "configured" {
+				want = theme.impls["cpp"].color
+			}
+
+			assert.Equal(t, want, theme.style(tt.series).color)
+		})
+	}
+
+	// variants get a palette color rather than the color of their base runner
+	assert.NotEqual(t, defaultImplColors["py"], theme.style("Python (PyPy)").color)
+	assert.Contains(t, theme.palette, theme.style("Python (PyPy)").color)
+}
@@ -5,9 +5,11 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
-	"math"
 	"os"
 	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/lmittmann/tint"
@@ -20,24 +22,49 @@ import (
 	"github.com/asphaltbuffet/elf/pkg/tasks"
 )
 
+const (
+	// benchmarkFileName is the file in an exercise directory that holds its benchmark results.
+	benchmarkFileName = "benchmark.json"
+
+	// inputBenchmarkDirName is the directory in an exercise that holds benchmark results for
+	// inputs given with WithBenchmarkInput, with a file for each input.
+	inputBenchmarkDirName = "benchmark-inputs"
+)
+
 type Benchmarker struct {
 	*Exercise
 	exerciseBaseDir string
+	impls           []string
+	part            runners.Part
 }
 
 type BenchmarkData struct {
 	Date time.Time `json:"run-date,omitempty"`
-	// Dir             string                `json:"dir"`
-	Title           string                `json:"title"`
-	Year            int                   `json:"year,omitempty"`
-	Day             int                   `json:"day"`
-	Runs            int                   `json:"numRuns"`
-	Normalization   float64               `json:"normalization,omitempty"`
+	// Dir is the exercise directory the data was loaded from. It isn't stored since
+	// benchmark files may be shared between machines.
+	Dir   string `json:"-"`
+	Title string `json:"title"`
+	Year  int    `json:"year,omitempty"`
+	Day   int    `json:"day"`
+	// Runs is the number of iterations of this run. Implementations that weren't rerun keep
+	// their own count.
+	Runs          int     `json:"numRuns"`
+	Normalization float64 `json:"normalization,omitempty"`
+	// Calibration is the CalibrationVersion the normalization factor was measured with.
+	Calibration     int                   `json:"calibration,omitempty"`
+	Machine         *Machine              `json:"machine,omitempty"`
 	Implementations []*ImplementationData `json:"implementations"`
 }
 
 type ImplementationData struct {
-	Name    string    `json:"name"`
+	Name string `json:"name"`
+	// Runner is the language or variant key the implementation was run with, e.g. "py".
+	Runner string `json:"runner,omitempty"`
+	// Version is the version of the interpreter or toolchain that ran the implementation, for
+	// runners that report one, e.g. "CPython 3.12.1".
+	Version string `json:"version,omitempty"`
+	// Runs is the number of iterations the implementation was last benchmarked with.
+	Runs    int       `json:"numRuns,omitempty"`
 	PartOne *PartData `json:"part-one"`
 	PartTwo *PartData `json:"part-two,omitempty"`
 }
@@ -47,9 +74,25 @@ type PartData struct {
 	Min  float64   `json:"min"`
 	Max  float64   `json:"max"`
 	Data []float64 `json:"data,omitempty"`
+
+	// Parse is the mean time spent parsing the input, for exercises with a parse step. Both
+	// parts share the parsed input, which is parsed once per run, so the timings above
+	// don't include it.
+	Parse float64 `json:"parse,omitempty"`
+
+	// Overhead is the mean time spent sending each task to the runner and reading its result.
+	// It isn't included in the other timings.
+	Overhead float64 `json:"overhead,omitempty"`
+
+	// Date is when the part was benchmarked, which may be before the run it is stored with
+	// if it wasn't rerun.
+	Date time.Time `json:"run-date,omitempty"`
 }
 
-var ErrRunnerStart = errors.New("runner start error")
+var (
+	ErrRunnerStart = errors.New("runner start error")
+	ErrInvalidPart = errors.New("invalid part")
+)
 
 func NewBenchmarker(config krampus.ExerciseConfiguration, options ...func(*Benchmarker)) (*Benchmarker, error) {
 	b := &Benchmarker{
@@ -66,6 +109,9 @@ func NewBenchmarker(config krampus.ExerciseConfiguration, options ...func(*Bench
 	}
 
 	switch {
+	case b.part != 0 && b.part != runners.PartOne && b.part != runners.PartTwo:
+		return nil, fmt.Errorf("benchmark part %d: %w", b.part, ErrInvalidPart)
+
 	case b.Path != "":
 		if err := b.Exercise.loadInfo(); err != nil {
 			return nil, err
@@ -84,14 +130,36 @@ func WithExerciseDir(dir string) func(*Benchmarker) {
 	}
 }
 
+// WithImplementations limits the benchmark to the given implementations (e.g. "go", "py").
+// If no implementations are given, all available implementations are benchmarked.
+func WithImplementations(impls []string) func(*Benchmarker) {
+	return func(b *Benchmarker) {
+		b.impls = impls
+	}
+}
+
+// WithPart limits the benchmark to a single exercise part. A zero part benchmarks both parts.
+func WithPart(part runners.Part) func(*Benchmarker) {
+	return func(b *Benchmarker) {
+		b.part = part
+	}
+}
+
+// WithBenchmarkInput overrides the input file listed in the exercise info. Results are stored
+// apart from those for the exercise's own input.
+func WithBenchmarkInput(file string) func(*Benchmarker) {
+	return func(b *Benchmarker) {
+		b.customInput = file
+	}
+}
+
 func (b *Benchmarker) Benchmark(afs afero.Fs, iterations int) ([]tasks.Result, error) {
 	logger := b.logger
 	normFactor := NormalizationFactor()
 
-	// TODO: add way to specify which implementations to run (e.g. --impls go,py or --impls all)
-	impls, err := b.GetImplementations()
+	impls, err := b.selectImplementations()
 	if err != nil {
-		return nil, fmt.Errorf("get impls: %w", err)
+		return nil, err
 	}
 
 	inputFile := filepath.Join(b.Path, b.Data.InputFileName)
@@ -132,72 +200,286 @@ func (b *Benchmarker) Benchmark(afs afero.Fs, iterations int) ([]tasks.Result, e
 		fmt.Println()
 	}
 
-	var benchmarkData []BenchmarkData
+	outfile := b.benchmarkFile()
+
+	prevData, err := readBenchmarkData(afs, outfile)
+	if err != nil {
+		logger.Error("reading existing benchmark data", slog.String("path", outfile), tint.Err(err))
+		return nil, err
+	}
+
+	// runs from other machines are kept as-is so benchmark files can be shared
+	machine := CurrentMachine()
+	localData, benchmarkData := splitByMachine(prevData, machine)
+
+	// keep results for implementations (and parts) that weren't part of this run
+	var (
+		prevImpls []*ImplementationData
+		prevRuns  int
+	)
+
+	if latest := latestBenchmarkData(localData); latest != nil {
+		prevImpls, prevRuns = latest.Implementations, latest.Runs
+	}
+
+	merged := mergeImplementationData(prevImpls, benchmarks)
+
+	// results stored before run counts were kept per implementation use the count of their run
+	for _, impl := range merged {
+		if impl.Runs == 0 {
+			impl.Runs = prevRuns
+		}
+	}
+
 	benchmarkData = append(benchmarkData, BenchmarkData{
 		Date:            time.Now().UTC(),
 		Day:             b.Day,
 		Title:           b.Title,
 		Year:            b.Year,
 		Runs:            iterations,
-		Implementations: benchmarks,
+		Implementations: merged,
 		Normalization:   normFactor,
+		Calibration:     CalibrationVersion,
+		Machine:         machine,
 	})
 
-	outfile := filepath.Join(b.Path, "benchmark.json")
-
-	// TODO: add flag to append/overwrite/fail?
-
 	jsonData, err := json.MarshalIndent(benchmarkData, "", "  ")
 	if err != nil {
 		logger.Error("marshalling benchmark data", tint.Err(err))
 		return nil, err
 	}
 
+	if err = afs.MkdirAll(filepath.Dir(outfile), 0o750); err != nil {
+		return nil, fmt.Errorf("creating benchmark directory: %w", err)
+	}
+
 	return results, afero.WriteFile(afs, outfile, jsonData, 0o600)
 }
 
-func NormalizationFactor() float64 {
-	start := time.Now()
-	m := map[int]string{}
+// benchmarkFile returns the file the benchmark results are stored in. Results for an input
+// other than the exercise's own go to a file named after it in the inputBenchmarkDirName
+// directory, e.g. benchmark-inputs/alt-input.json for alt-input.txt, so they aren't compared
+// with the others.
+func (b *Benchmarker) benchmarkFile() string {
+	if b.customInput == "" {
+		return filepath.Join(b.Path, benchmarkFileName)
+	}
+
+	name := strings.TrimSuffix(filepath.Base(b.customInput), filepath.Ext(b.customInput))
+
+	return filepath.Join(b.Path, inputBenchmarkDirName, name+".json")
+}
+
+// selectImplementations returns the implementations to benchmark, each followed by its
+// configured variants. Requested implementations, or the base of requested variants, must
+// exist in the exercise directory.
+func (b *Benchmarker) selectImplementations() ([]string, error) {
+	available, err := b.GetImplementations()
+	if err != nil {
+		return nil, fmt.Errorf("get impls: %w", err)
+	}
+
+	if len(b.impls) == 0 {
+		withVariants := make([]string, 0, len(available))
+
+		for _, impl := range available {
+			withVariants = append(withVariants, impl)
+			withVariants = append(withVariants, runners.VariantsOf(impl)...)
+		}
+
+		return withVariants, nil
+	}
+
+	selected := make([]string, 0, len(b.impls))
+
+	for _, impl := range b.impls {
+		impl = strings.ToLower(strings.TrimSpace(impl))
 
-	for i := 1; i < math.MaxInt16; i++ {
-		m[i] = fmt.Sprintf("%2.3f", 1/float64(i))
+		lang := impl
+		if v, ok := runners.LookupVariant(impl); ok {
+			lang = v.Runner
+		}
+
+		if !slices.Contains(available, lang) {
+			return nil, fmt.Errorf("search %s for %q: %w", b.Path, impl, ErrNoImplementations)
+		}
 
-		if _, ok := m[i/3]; ok {
-			delete(m, i/2) //nolint:mnd // hard-coding for now
+		if !slices.Contains(selected, impl) {
+			selected = append(selected, impl)
 		}
 	}
 
-	elapsed := time.Since(start)
+	return selected, nil
+}
+
+// benchmarkParts returns the exercise parts to benchmark.
+func (b *Benchmarker) benchmarkParts() []runners.Part {
+	if b.part != 0 {
+		return []runners.Part{b.part}
+	}
 
-	return elapsed.Seconds()
+	return []runners.Part{runners.PartOne, runners.PartTwo}
+}
+
+// readBenchmarkData loads previously saved benchmark data. A missing file is not an error.
+func readBenchmarkData(afs afero.Fs, path string) ([]BenchmarkData, error) {
+	raw, err := afero.ReadFile(afs, path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	var data []BenchmarkData
+
+	if err = json.Unmarshal(raw, &data); err != nil {
+		return nil, fmt.Errorf("unmarshal %s: %w", path, err)
+	}
+
+	return data, nil
+}
+
+// latestBenchmarkData returns the most recent benchmark run, or nil if there are none.
+func latestBenchmarkData(data []BenchmarkData) *BenchmarkData {
+	var latest *BenchmarkData
+
+	for i := range data {
+		if latest == nil || data[i].Date.After(latest.Date) {
+			latest = &data[i]
+		}
+	}
+
+	return latest
+}
+
+// splitByMachine separates benchmark runs made on the given machine from runs made elsewhere.
+// Runs without machine information are assumed to be local.
+func splitByMachine(data []BenchmarkData, m *Machine) ([]BenchmarkData, []BenchmarkData) {
+	var local, other []BenchmarkData
+
+	for _, d := range data {
+		if d.Machine == nil || d.Machine.Equal(m) {
+			local = append(local, d)
+		} else {
+			other = append(other, d)
+		}
+	}
+
+	return local, other
+}
+
+// legacyPythonName matches the names Python results were stored under by older versions of
+// elf, e.g. "CPython 3.12.1". Some also stored them as "Python" with that as the version. They
+// are now named after the interpreter implementation, e.g. "Python (CPython)".
+var legacyPythonName = regexp.MustCompile(`^(CPython|PyPy|GraalPy|IronPython|Jython) \d+\.\d+`)
+
+const pythonImplName = "Python"
+
+// currentPythonName returns the name Python results stored under a legacy name are now stored
+// under, or an empty string for other results.
+func currentPythonName(impl *ImplementationData) string {
+	legacy := impl.Name
+	if legacy == pythonImplName {
+		legacy = impl.Version
+	}
+
+	m := legacyPythonName.FindStringSubmatch(legacy)
+	if m == nil {
+		return ""
+	}
+
+	return pythonImplName + " (" + m[1] + ")"
+}
+
+// mergeImplementationData overlays new implementation results onto previous ones. Previous
+// implementations that weren't rerun are kept, as are parts that weren't rerun. Python results
+// stored under a legacy name are replaced by new results from the same interpreter.
+func mergeImplementationData(prev, curr []*ImplementationData) []*ImplementationData {
+	merged := make([]*ImplementationData, 0, len(prev)+len(curr))
+
+	rerun := func(name string) bool {
+		return slices.ContainsFunc(curr, func(c *ImplementationData) bool { return c != nil && c.Name == name })
+	}
+
+	for _, p := range prev {
+		if p == nil {
+			continue
+		}
+
+		if name := currentPythonName(p); name != "" && rerun(name) {
+			continue
+		}
+
+		impl := *p
+		merged = append(merged, &impl)
+	}
+
+	for _, c := range curr {
+		if c == nil {
+			continue
+		}
+
+		idx := slices.IndexFunc(merged, func(m *ImplementationData) bool { return m.Name == c.Name })
+		if idx == -1 {
+			merged = append(merged, c)
+			continue
+		}
+
+		if c.Runner != "" {
+			merged[idx].Runner = c.Runner
+		}
+
+		if c.Version != "" {
+			merged[idx].Version = c.Version
+		}
+
+		if c.Runs != 0 {
+			merged[idx].Runs = c.Runs
+		}
+
+		if c.PartOne != nil {
+			merged[idx].PartOne = c.PartOne
+		}
+
+		if c.PartTwo != nil {
+			merged[idx].PartTwo = c.PartTwo
+		}
+	}
+
+	return merged
 }
 
 func (b *Benchmarker) runBenchmark(iterations int) ([]tasks.Result, *ImplementationData, error) {
 	logger := b.logger
 
+	// files changed while the benchmark runs are newer than its results
+	started := time.Now().UTC()
+
 	const numParts int = 2
 
 	var (
 		benchmarkTasks []*runners.Task
 		metricsResults = make(map[runners.Part][]float64, numParts*iterations)
+		overheads      = make(map[runners.Part][]float64, numParts*iterations)
+		parses         []float64
 		results        = make([]tasks.Result, 0, numParts*iterations)
 	)
 
+	// the input is only sent with the first task; later tasks refer to it by ID
+	inputID := runners.InputID(b.Data.InputData)
+
 	// generate all the tasks needed for this benchmark run
 	for i := range iterations {
-		benchmarkTasks = append(
-			benchmarkTasks,
-			&runners.Task{
-				TaskID: tasks.MakeTaskID(tasks.Benchmark, runners.PartOne, i),
-				Part:   runners.PartOne,
-				Input:  b.Data.InputData,
-			},
-			&runners.Task{
-				TaskID: tasks.MakeTaskID(tasks.Benchmark, runners.PartTwo, i),
-				Part:   runners.PartTwo,
-				Input:  b.Data.InputData,
+		for _, part := range b.benchmarkParts() {
+			benchmarkTasks = append(benchmarkTasks, &runners.Task{
+				TaskID:  tasks.MakeTaskID(tasks.Benchmark, part, i),
+				Part:    part,
+				Input:   b.Data.InputData,
+				InputID: inputID,
 			})
+		}
 	}
 
 	progBar := progressbar.NewOptions(
@@ -230,7 +512,12 @@ func (b *Benchmarker) runBenchmark(iterations int) ([]tasks.Result, *Implementat
 			r := handleTaskResult(os.Stdout, benchResult, "")
 			results = append(results, r)
 
-			metricsResults[r.Part] = append(metricsResults[r.Part], benchResult.Duration)
+			metricsResults[t.Part] = append(metricsResults[t.Part], benchResult.Duration)
+			overheads[t.Part] = append(overheads[t.Part], benchResult.Overhead)
+		}
+
+		if benchResult.ParseDuration > 0 {
+			parses = append(parses, benchResult.ParseDuration)
 		}
 
 		if err = progBar.Add(1); err != nil {
@@ -239,45 +526,86 @@ func (b *Benchmarker) runBenchmark(iterations int) ([]tasks.Result, *Implementat
 		}
 	}
 
-	stats, err := calculateMetrics(metricsResults)
+	partStats, err := calculateMetrics(metricsResults)
 	if err != nil {
 		logger.Error("getting stats from results", tint.Err(err))
 		return results, nil, err
 	}
 
+	for part, data := range partStats {
+		data.Overhead = mean(overheads[part])
+		data.Parse = mean(parses)
+		data.Date = started
+	}
+
+	b.printOverhead(partStats)
+
 	return results,
 		&ImplementationData{
 			Name:    b.runner.String(),
-			PartOne: stats[runners.PartOne],
-			PartTwo: stats[runners.PartTwo],
+			Runner:  b.Language,
+			Version: runners.VersionOf(b.runner),
+			Runs:    iterations,
+			PartOne: partStats[runners.PartOne],
+			PartTwo: partStats[runners.PartTwo],
 		}, nil
 }
 
+// printOverhead shows the mean time spent passing each task to the runner, which the
+// benchmark timings leave out.
+func (b *Benchmarker) printOverhead(partStats map[runners.Part]*PartData) {
+	var parts []string
+
+	for _, part := range b.benchmarkParts() {
+		if data, ok := partStats[part]; ok {
+			overhead := time.Duration(data.Overhead * float64(time.Second))
+			parts = append(parts, fmt.Sprintf("part %d %s", part, overhead.Round(time.Microsecond)))
+		}
+	}
+
+	if len(parts) != 0 {
+		fmt.Fprintf(b.writer, "\ntransfer overhead per task (not included): %s", strings.Join(parts, ", "))
+	}
+}
+
+func mean(data []float64) float64 {
+	if len(data) == 0 {
+		return 0
+	}
+
+	var sum float64
+	for _, d := range data {
+		sum += d
+	}
+
+	return sum / float64(len(data))
+}
+
 func calculateMetrics(results map[runners.Part][]float64) (map[runners.Part]*PartData, error) {
 	metrics := make(map[runners.Part]*PartData)
 
 	for part, durations := range results {
 		data := stats.LoadRawData(durations)
 
-		mean, err := data.Mean()
+		meanValue, err := data.Mean()
 		if err != nil {
 			return nil, err
 		}
 
-		max, err := data.Max()
+		maxValue, err := data.Max()
 		if err != nil {
 			return nil, err
 		}
 
-		min, err := data.Min()
+		minValue, err := data.Min()
 		if err != nil {
 			return nil, err
 		}
 
 		metrics[part] = &PartData{
-			Mean: mean,
-			Min:  min,
-			Max:  max,
+			Mean: meanValue,
+			Min:  minValue,
+			Max:  maxValue,
 			Data: durations,
 		}
 	}
@@ -286,11 +614,20 @@ func calculateMetrics(results map[runners.Part][]float64) (map[runners.Part]*Par
 }
 
 func (b *BenchmarkData) String() string {
-	return fmt.Sprintf("BenchmarkData{Date: %s, AOC %d/%02d, Runs: %3d, Normalization: %.6f, Implementations: %s}",
-		b.Date.Local().Format(time.DateOnly), b.Year, b.Day, b.Runs, b.Normalization, b.Implementations)
+	return fmt.Sprintf("BenchmarkData{Date: %s, AOC %d/%02d, Runs: %3d, Normalization: %.6f, Machine: %s, Implementations: %s}",
+		b.Date.Local().Format(time.DateOnly), b.Year, b.Day, b.Runs, b.Normalization, b.Machine, b.Implementations)
 }
 
 func (i *ImplementationData) String() string {
 	return fmt.Sprintf("%s{%d PartOne, %d PartTwo}",
-		i.Name, len(i.PartOne.Data), len(i.PartTwo.Data))
+		i.Name, i.PartOne.count(), i.PartTwo.count())
+}
+
+// count returns the number of data points, allowing for parts that weren't benchmarked.
+func (p *PartData) count() int {
+	if p == nil {
+		return 0
+	}
+
+	return len(p.Data)
 }
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"io"
 	"log/slog"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -207,6 +208,28 @@ func TestNewBenchmarker(t *testing.T) {
 			wants:     wants{path: "exercises/2017/01-fakeFullDay", exerciseID: "2017-01"},
 			assertion: require.NoError,
 		},
+		{
+			name: "valid part",
+			args: args{
+				options: []func(*Benchmarker){
+					WithExerciseDir("exercises/2017/01-fakeFullDay"),
+					WithPart(runners.PartTwo),
+				},
+			},
+			wants:     wants{path: "exercises/2017/01-fakeFullDay", exerciseID: "2017-01"},
+			assertion: require.NoError,
+		},
+		{
+			name: "invalid part",
+			args: args{
+				options: []func(*Benchmarker){
+					WithExerciseDir("exercises/2017/01-fakeFullDay"),
+					WithPart(runners.Visualize),
+				},
+			},
+			wants:     wants{},
+			assertion: require.Error,
+		},
 	}
 
 	teardownTestCase := setupTestCase(t)
@@ -276,6 +299,7 @@ func TestBenchmarkDataString(t *testing.T) {
 		Day             int
 		Runs            int
 		Normalization   float64
+		Machine         *Machine
 		Implementations []*ImplementationData
 	}
 
@@ -295,7 +319,21 @@ func TestBenchmarkDataString(t *testing.T) {
 				Normalization:   0.42,
 				Implementations: []*ImplementationData{},
 			},
-			want: "BenchmarkData{Date: 2021-12-25, AOC 2015/02, Runs:  69, Normalization: 0.420000, Implementations: []}",
+			want: "BenchmarkData{Date: 2021-12-25, AOC 2015/02, Runs:  69, Normalization: 0.420000, Machine: unknown, Implementations: []}",
+		},
+		{
+			name: "with machine",
+			fields: fields{
+				Date:            time.Date(2021, time.December, 25, 12, 34, 56, 0, time.UTC),
+				Title:           "fake title",
+				Year:            2015,
+				Day:             2,
+				Runs:            69,
+				Normalization:   0.42,
+				Machine:         &Machine{Host: "elfbox", OS: "linux", Arch: "amd64", CPUs: 4},
+				Implementations: []*ImplementationData{},
+			},
+			want: "BenchmarkData{Date: 2021-12-25, AOC 2015/02, Runs:  69, Normalization: 0.420000, Machine: elfbox (linux/amd64), Implementations: []}",
 		},
 	}
 
@@ -308,6 +346,7 @@ func TestBenchmarkDataString(t *testing.T) {
 				Day:             tt.fields.Day,
 				Runs:            tt.fields.Runs,
 				Normalization:   tt.fields.Normalization,
+				Machine:         tt.fields.Machine,
 				Implementations: tt.fields.Implementations,
 			}
 			assert.Equal(t, tt.want, b.String())
@@ -372,6 +411,8 @@ func TestRunBenchmark(t *testing.T) {
 			wantResults: []tasks.Result{},
 			wantData: &ImplementationData{
 				Name:    "MOCK",
+				Runner:  "go",
+				Runs:    1,
 				PartOne: nil,
 				PartTwo: nil,
 			},
@@ -477,3 +518,281 @@ func Test_calculateMetrics(t *testing.T) {
 		})
 	}
 }
+
+func TestSelectImplementations(t *testing.T) {
+	tests := []struct {
+		name      string
+		impls     []string
+		want      []string
+		assertion require.ErrorAssertionFunc
+	}{
+		{
+			name:      "all implementations",
+			impls:     nil,
+			want:      []string{"go", "py"},
+			assertion: require.NoError,
+		},
+		{
+			name:      "single implementation",
+			impls:     []string{"py"},
+			want:      []string{"py"},
+			assertion: require.NoError,
+		},
+		{
+			name:      "duplicates and case",
+			impls:     []string{"Go", "go", " py"},
+			want:      []string{"go", "py"},
+			assertion: require.NoError,
+		},
+		{
+			name:      "missing implementation",
+			impls:     []string{"go", "rs"},
+			want:      nil,
+			assertion: require.Error,
+		},
+	}
+
+	teardownTestCase := setupTestCase(t)
+	defer teardownTestCase(t)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			teardownSubTest := setupSubTest(t)
+			defer teardownSubTest(t)
+
+			b := &Benchmarker{
+				Exercise: &Exercise{
+					Path:   "exercises/2017/01-fakeFullDay",
+					appFs:  testFs,
+					logger: slog.New(slog.NewTextHandler(io.Discard, nil)),
+				},
+				impls: tt.impls,
+			}
+
+			got, err := b.selectImplementations()
+
+			tt.assertion(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestSelectImplementations_variants(t *testing.T) {
+	require.NoError(t, runners.RegisterVariant("go-select-test", runners.Variant{
+		Runner:     "go",
+		BuildFlags: []string{"-gcflags=-B"},
+	}))
+	require.NoError(t, runners.RegisterVariant("js-select-test", runners.Variant{Runner: "js"}))
+
+	tests := []struct {
+		name      string
+		impls     []string
+		want      []string
+		assertion require.ErrorAssertionFunc
+	}{
+		{
+			name:      "all implementations with variants",
+			impls:     nil,
+			want:      []string{"go", "go-select-test", "py"},
+			assertion: require.NoError,
+		},
+		{
+			name:      "variant only",
+			impls:     []string{"Go-Select-Test"},
+			want:      []string{"go-select-test"},
+			assertion: require.NoError,
+		},
+		{
+			name:      "variant without implementation",
+			impls:     []string{"js-select-test"},
+			want:      nil,
+			assertion: require.Error,
+		},
+	}
+
+	teardownTestCase := setupTestCase(t)
+	defer teardownTestCase(t)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			teardownSubTest := setupSubTest(t)
+			defer teardownSubTest(t)
+
+			b := &Benchmarker{
+				Exercise: &Exercise{
+					Path:   "exercises/2017/01-fakeFullDay",
+					appFs:  testFs,
+					logger: slog.New(slog.NewTextHandler(io.Discard, nil)),
+				},
+				impls: tt.impls,
+			}
+
+			got, err := b.selectImplementations()
+
+			tt.assertion(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestBenchmarkParts(t *testing.T) {
+	tests := []struct {
+		name string
+		part runners.Part
+		want []runners.Part
+	}{
+		{"both parts", 0, []runners.Part{runners.PartOne, runners.PartTwo}},
+		{"part one", runners.PartOne, []runners.Part{runners.PartOne}},
+		{"part two", runners.PartTwo, []runners.Part{runners.PartTwo}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := &Benchmarker{part: tt.part}
+
+			assert.Equal(t, tt.want, b.benchmarkParts())
+		})
+	}
+}
+
+func TestLatestBenchmarkData(t *testing.T) {
+	older := BenchmarkData{Date: time.Date(2023, time.December, 1, 0, 0, 0, 0, time.UTC), Runs: 1}
+	newer := BenchmarkData{Date: time.Date(2023, time.December, 2, 0, 0, 0, 0, time.UTC), Runs: 2}
+
+	assert.Nil(t, latestBenchmarkData(nil))
+	assert.Equal(t, &newer, latestBenchmarkData([]BenchmarkData{older, newer}))
+	assert.Equal(t, &newer, latestBenchmarkData([]BenchmarkData{newer, older}))
+}
+
+func TestMergeImplementationData(t *testing.T) {
+	goOld := &ImplementationData{
+		Name:    "Go",
+		PartOne: &PartData{Mean: 1},
+		PartTwo: &PartData{Mean: 2},
+	}
+	pyOld := &ImplementationData{
+		Name:    "Python",
+		PartOne: &PartData{Mean: 10},
+		PartTwo: &PartData{Mean: 20},
+	}
+
+	tests := []struct {
+		name string
+		prev []*ImplementationData
+		curr []*ImplementationData
+		want []*ImplementationData
+	}{
+		{
+			name: "no previous data",
+			prev: nil,
+			curr: []*ImplementationData{goOld},
+			want: []*ImplementationData{goOld},
+		},
+		{
+			name: "keep implementations that weren't rerun",
+			prev: []*ImplementationData{goOld, pyOld},
+			curr: []*ImplementationData{{Name: "Go", PartOne: &PartData{Mean: 3}, PartTwo: &PartData{Mean: 4}}},
+			want: []*ImplementationData{
+				{Name: "Go", PartOne: &PartData{Mean: 3}, PartTwo: &PartData{Mean: 4}},
+				pyOld,
+			},
+		},
+		{
+			name: "keep parts that weren't rerun",
+			prev: []*ImplementationData{goOld, pyOld},
+			curr: []*ImplementationData{{Name: "Python", PartOne: nil, PartTwo: &PartData{Mean: 30}}},
+			want: []*ImplementationData{
+				goOld,
+				{Name: "Python", PartOne: &PartData{Mean: 10}, PartTwo: &PartData{Mean: 30}},
+			},
+		},
+		{
+			name: "new implementation",
+			prev: []*ImplementationData{goOld},
+			curr: []*ImplementationData{pyOld},
+			want: []*ImplementationData{goOld, pyOld},
+		},
+		{
+			name: "update version",
+			prev: []*ImplementationData{{Name: "Python (CPython)", Version: "3.11.7", PartOne: &PartData{Mean: 10}}},
+			curr: []*ImplementationData{{Name: "Python (CPython)", Version: "3.12.1", PartOne: &PartData{Mean: 9}}},
+			want: []*ImplementationData{{Name: "Python (CPython)", Version: "3.12.1", PartOne: &PartData{Mean: 9}}},
+		},
+		{
+			name: "keep results of other python interpreters",
+			prev: []*ImplementationData{{Name: "Python (CPython)", Runner: "py", Version: "3.12.1", PartOne: &PartData{Mean: 10}}},
+			curr: []*ImplementationData{{Name: "Python (PyPy)", Runner: "py", Version: "3.10.14", PartOne: &PartData{Mean: 2}}},
+			want: []*ImplementationData{
+				{Name: "Python (CPython)", Runner: "py", Version: "3.12.1", PartOne: &PartData{Mean: 10}},
+				{Name: "Python (PyPy)", Runner: "py", Version: "3.10.14", PartOne: &PartData{Mean: 2}},
+			},
+		},
+		{
+			name: "replace legacy python names of the same interpreter",
+			prev: []*ImplementationData{
+				goOld,
+				{Name: "CPython 3.11.7", PartOne: &PartData{Mean: 10}},
+				{Name: "Python", Version: "CPython 3.11.8", PartOne: &PartData{Mean: 11}},
+				{Name: "PyPy 3.10.14", PartOne: &PartData{Mean: 5}},
+			},
+			curr: []*ImplementationData{{Name: "Python (CPython)", Version: "3.12.1", PartOne: &PartData{Mean: 9}}},
+			want: []*ImplementationData{
+				goOld,
+				{Name: "PyPy 3.10.14", PartOne: &PartData{Mean: 5}},
+				{Name: "Python (CPython)", Version: "3.12.1", PartOne: &PartData{Mean: 9}},
+			},
+		},
+		{
+			name: "keep legacy python names until python is rerun",
+			prev: []*ImplementationData{goOld, {Name: "CPython 3.11.7", PartOne: &PartData{Mean: 10}}},
+			curr: []*ImplementationData{goOld},
+			want: []*ImplementationData{goOld, {Name: "CPython 3.11.7", PartOne: &PartData{Mean: 10}}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, mergeImplementationData(tt.prev, tt.curr))
+		})
+	}
+
+	// previous data must not be modified
+	assert.Equal(t, &PartData{Mean: 10}, pyOld.PartOne)
+	assert.Equal(t, &PartData{Mean: 20}, pyOld.PartTwo)
+}
+
+func TestBenchmarker_benchmarkFile(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{name: "exercise input", input: "", want: filepath.Join("exercise", "benchmark.json")},
+		{name: "custom input", input: "alt-input.txt", want: filepath.Join("exercise", "benchmark-inputs", "alt-input.json")},
+		{name: "custom input in another directory", input: filepath.Join("..", "inputs", "big"), want: filepath.Join("exercise", "benchmark-inputs", "big.json")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := &Benchmarker{Exercise: &Exercise{Path: "exercise", customInput: tt.input}}
+
+			assert.Equal(t, tt.want, b.benchmarkFile())
+		})
+	}
+}
+
+func TestSplitByMachine(t *testing.T) {
+	local := &Machine{Host: "local", OS: "linux", Arch: "amd64", CPUs: 8}
+	remote := &Machine{Host: "remote", OS: "darwin", Arch: "arm64", CPUs: 10}
+
+	data := []BenchmarkData{
+		{Day: 1, Machine: nil},
+		{Day: 2, Machine: local},
+		{Day: 3, Machine: remote},
+	}
+
+	gotLocal, gotOther := splitByMachine(data, local)
+
+	assert.Equal(t, []BenchmarkData{data[0], data[1]}, gotLocal)
+	assert.Equal(t, []BenchmarkData{data[2]}, gotOther)
+}
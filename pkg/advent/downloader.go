@@ -22,6 +22,7 @@ import (
 	"github.com/spf13/afero"
 
 	"github.com/asphaltbuffet/elf/pkg/krampus"
+	"github.com/asphaltbuffet/elf/pkg/runners"
 	"github.com/asphaltbuffet/elf/pkg/utilities"
 )
 
@@ -44,6 +45,7 @@ type Downloader struct {
 	token           string
 	overwrites      *Overwrites
 	skipImpl        bool
+	goHelper        string
 }
 
 type Overwrites struct {
@@ -75,6 +77,7 @@ func NewDownloader(config krampus.DownloadConfiguration, options ...func(*Downlo
 		rClient:         resty.New().SetBaseURL("https://adventofcode.com"),
 		token:           config.GetToken(),
 		inputFileName:   config.GetInputFilename(),
+		goHelper:        config.GetGoHelper(),
 	}
 
 	for _, option := range options {
@@ -124,6 +127,11 @@ func WithSkipImpl(skip bool) func(*Downloader) {
 	}
 }
 
+// GoHelper returns the import path of the helper package used by the Go exercise template.
+func (d *Downloader) GoHelper() string {
+	return d.goHelper
+}
+
 func (d *Downloader) validate() error {
 	var err []error
 
@@ -483,6 +491,18 @@ var goTemplate []byte
 //go:embed templates/py.tmpl
 var pyTemplate []byte
 
+//go:embed templates/js.tmpl
+var jsTemplate []byte
+
+//go:embed templates/ts.tmpl
+var tsTemplate []byte
+
+//go:embed templates/c.tmpl
+var cTemplate []byte
+
+//go:embed templates/cpp.tmpl
+var cppTemplate []byte
+
 type tmplFile struct {
 	Name     string
 	Path     string
@@ -506,7 +526,15 @@ func (d *Downloader) addMissingFiles() error {
 
 	var err error
 
-	implPath := filepath.Join(d.Path, d.Language)
+	implDir := d.Language
+
+	// configured runners may keep implementations in a differently named directory
+	def, isConfigured := runners.Lookup(d.Language)
+	if isConfigured {
+		implDir = def.Dir
+	}
+
+	implPath := filepath.Join(d.Path, implDir)
 
 	if err = d.appFs.MkdirAll(implPath, 0o750); err != nil {
 		logger.Error("add exercise implementation path", tint.Err(err))
@@ -552,8 +580,65 @@ func (d *Downloader) addMissingFiles() error {
 			Replace:  false,
 		})
 
+	case "js":
+		tmpls = append(tmpls, tmplFile{
+			Name:     "js",
+			Path:     "js",
+			Data:     jsTemplate,
+			FileName: "index.js",
+			Replace:  false,
+		})
+
+	case "ts":
+		tmpls = append(tmpls, tmplFile{
+			Name:     "ts",
+			Path:     "ts",
+			Data:     tsTemplate,
+			FileName: "index.ts",
+			Replace:  false,
+		})
+
+	case "c":
+		tmpls = append(tmpls, tmplFile{
+			Name:     "c",
+			Path:     "c",
+			Data:     cTemplate,
+			FileName: "exercise.c",
+			Replace:  false,
+		})
+
+	case "cpp":
+		tmpls = append(tmpls, tmplFile{
+			Name:     "cpp",
+			Path:     "cpp",
+			Data:     cppTemplate,
+			FileName: "exercise.cpp",
+			Replace:  false,
+		})
+
+	case "wasm":
+		// modules are built with the toolchain of the source language, so there is nothing
+		// to scaffold beyond the implementation directory
+
 	default:
-		return fmt.Errorf("template %s files: %w", d.Language, ErrInvalidLanguage)
+		if !isConfigured {
+			return fmt.Errorf("template %s files: %w", d.Language, ErrInvalidLanguage)
+		}
+
+		if def.Template != "" {
+			data, readErr := afero.ReadFile(d.appFs, def.Template)
+			if readErr != nil {
+				return fmt.Errorf("reading %s template: %w", d.Language, readErr)
+			}
+
+			tmpls = append(tmpls, tmplFile{
+				Name:     d.Language,
+				Path:     def.Dir,
+				Data:     data,
+				FileName: runners.OutputFilename(def.Template),
+				Replace:  false,
+			})
+		}
 	}
 
 	for _, t := range tmpls {
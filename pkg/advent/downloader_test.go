@@ -865,6 +865,7 @@ func TestNewDownloader(t *testing.T) {
 			mockConfig.EXPECT().GetToken().Return("TEST_token")
 			mockConfig.EXPECT().GetBaseDir().Return("TEST_exercises")
 			mockConfig.EXPECT().GetLanguage().Return("go")
+			mockConfig.EXPECT().GetGoHelper().Return("example.com/helpers/common")
 
 			require.NoError(t, testFs.MkdirAll("TEST_exercises", 0o755))
 
@@ -879,6 +880,7 @@ func TestNewDownloader(t *testing.T) {
 			assert.Equal(t, tt.want.exerciseBaseDir, got.exerciseBaseDir)
 			assert.Equal(t, tt.want.cfgDir, got.cfgDir)
 			assert.Equal(t, tt.want.skipImpl, got.skipImpl)
+			assert.Equal(t, "example.com/helpers/common", got.GoHelper())
 
 			assert.NotNil(t, got.logger)
 		})
@@ -921,3 +923,64 @@ func TestDownloader_validate(t *testing.T) {
 		})
 	}
 }
+
+func TestDownloader_addTemplatedFile_goHelper(t *testing.T) {
+	tests := []struct {
+		name       string
+		goHelper   string
+		contains   []string
+		notContain []string
+	}{
+		{
+			name:     "with helper",
+			goHelper: "example.com/solutions/internal/common",
+			contains: []string{
+				"\thelper \"example.com/solutions/internal/common\"\n",
+				"\thelper.BaseExercise\n",
+			},
+		},
+		{
+			name:     "helper path not ending in package name",
+			goHelper: "example.com/aoc-helpers.v2",
+			contains: []string{
+				"\thelper \"example.com/aoc-helpers.v2\"\n",
+				"\thelper.BaseExercise\n",
+			},
+		},
+		{
+			name:       "without helper",
+			goHelper:   "",
+			contains:   []string{"type Exercise struct {\n}\n"},
+			notContain: []string{"BaseExercise"},
+		},
+	}
+
+	teardownTestCase := setupTestCase(t)
+	defer teardownTestCase(t)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			teardownSubTest := setupSubTest(t)
+			defer teardownSubTest(t)
+
+			mockDlr.goHelper = tt.goHelper
+			mockDlr.Path = "exercise"
+			mockDlr.Year, mockDlr.Day = 2015, 1
+			require.NoError(t, testFs.MkdirAll(filepath.Join("exercise", "go"), 0o755))
+
+			err := mockDlr.addTemplatedFile(tmplFile{Name: "go", Path: "go", Data: goTemplate, FileName: "exercise.go"})
+			require.NoError(t, err)
+
+			got, err := afero.ReadFile(testFs, filepath.Join("exercise", "go", "exercise.go"))
+			require.NoError(t, err)
+
+			for _, s := range tt.contains {
+				assert.Contains(t, string(got), s)
+			}
+
+			for _, s := range tt.notContain {
+				assert.NotContains(t, string(got), s)
+			}
+		})
+	}
+}
@@ -0,0 +1,144 @@
+package advent
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Machine identifies the system a benchmark was run on.
+type Machine struct {
+	Host string `json:"host"`
+	OS   string `json:"os"`
+	Arch string `json:"arch"`
+	CPUs int    `json:"cpus"`
+}
+
+// CurrentMachine returns the identity of the system elf is running on.
+func CurrentMachine() *Machine {
+	host, err := os.Hostname()
+	if err != nil {
+		host = "unknown"
+	}
+
+	return &Machine{
+		Host: host,
+		OS:   runtime.GOOS,
+		Arch: runtime.GOARCH,
+		CPUs: runtime.NumCPU(),
+	}
+}
+
+// Equal reports whether both machines describe the same system.
+func (m *Machine) Equal(other *Machine) bool {
+	if m == nil || other == nil {
+		return m == other
+	}
+
+	return *m == *other
+}
+
+func (m *Machine) String() string {
+	if m == nil {
+		return "unknown"
+	}
+
+	return fmt.Sprintf("%s (%s/%s)", m.Host, m.OS, m.Arch)
+}
+
+// CalibrationVersion identifies the workloads measured by NormalizationFactor. It is stored
+// with each factor and must be bumped whenever the workloads change, since factors measured
+// with different workloads can't be compared.
+//
+// Data without a version was calibrated with a single map workload (version 1).
+const CalibrationVersion = 2
+
+// calibrationSink keeps the compiler from optimizing away calibration workloads.
+var calibrationSink int
+
+// calibrationWorkloads exercise different parts of the machine (allocation, hashing, sorting,
+// string handling, and floating point) so the normalization factor isn't skewed by a single
+// kind of work.
+//
+//nolint:mnd // workload sizes are arbitrary
+var calibrationWorkloads = []func() int{
+	func() int {
+		m := map[int]string{}
+
+		for i := 1; i < math.MaxInt16; i++ {
+			m[i] = fmt.Sprintf("%2.3f", 1/float64(i))
+
+			if _, ok := m[i/3]; ok {
+				delete(m, i/2)
+			}
+		}
+
+		return len(m)
+	},
+	func() int {
+		const n = 1 << 15
+
+		s := make([]int, n)
+		x := uint32(1)
+
+		for i := range s {
+			// xorshift keeps the input deterministic
+			x ^= x << 13
+			x ^= x >> 17
+			x ^= x << 5
+			s[i] = int(x)
+		}
+
+		slices.Sort(s)
+
+		return s[n/2]
+	},
+	func() int {
+		var sb strings.Builder
+
+		for i := range 1 << 14 {
+			sb.WriteString(strconv.Itoa(i))
+		}
+
+		return strings.Count(sb.String(), "7")
+	},
+	func() int {
+		var sum float64
+
+		for i := 1; i < 1<<18; i++ {
+			sum += math.Sqrt(float64(i)) / float64(i)
+		}
+
+		return int(sum)
+	},
+}
+
+// NormalizationFactor measures how long this machine takes to run a fixed suite of small
+// workloads, in seconds. Dividing benchmark durations by this factor allows runs from
+// different machines to be compared.
+//
+// Each workload is run several times and only the fastest run is kept to reduce noise.
+func NormalizationFactor() float64 {
+	const rounds = 5
+
+	var total float64
+
+	for _, workload := range calibrationWorkloads {
+		fastest := math.MaxFloat64
+
+		for range rounds {
+			start := time.Now()
+			calibrationSink += workload()
+			fastest = min(fastest, time.Since(start).Seconds())
+		}
+
+		total += fastest
+	}
+
+	return total
+}
@@ -0,0 +1,84 @@
+package advent
+
+import (
+	"io"
+
+	"github.com/schollz/progressbar/v3"
+
+	"github.com/asphaltbuffet/elf/pkg/runners"
+	"github.com/asphaltbuffet/elf/pkg/tasks"
+)
+
+// fractionSteps is the resolution of progress bars for tasks reporting a fraction.
+const fractionSteps = 1000
+
+// taskProgress shows the progress reported by a running task as a progress bar.
+type taskProgress struct {
+	w    io.Writer
+	desc string
+
+	bar *progressbar.ProgressBar
+	// max is the number of steps shown by the bar, or -1 for a spinner when the number of
+	// steps is unknown.
+	max int64
+	// fraction is whether the bar shows a fraction rather than counting steps.
+	fraction bool
+}
+
+// trackProgress shows the progress reported by a task while it runs. The bar only appears
+// once the task reports progress. The returned function removes it, and must be called
+// before the result of the task is printed.
+func trackProgress(w io.Writer, task *runners.Task) func() {
+	_, part, subpart := tasks.ParseTaskID(task.TaskID)
+
+	tp := &taskProgress{w: w, desc: taskStyle(int(part), subpart).String()}
+	task.OnProgress = tp.update
+
+	return tp.clear
+}
+
+func (tp *taskProgress) update(p runners.Progress) {
+	value, maxValue, fraction := p.Done, p.Total, false
+
+	switch {
+	case p.Total > 0:
+	case p.Fraction > 0:
+		ratio, _ := p.Ratio()
+		value, maxValue, fraction = int64(ratio*fractionSteps), fractionSteps, true
+	default:
+		maxValue = -1
+	}
+
+	if tp.bar == nil || maxValue != tp.max || fraction != tp.fraction {
+		tp.clear()
+		tp.bar = tp.newBar(maxValue, fraction)
+		tp.max, tp.fraction = maxValue, fraction
+	}
+
+	_ = tp.bar.Set64(value)
+}
+
+func (tp *taskProgress) newBar(maxValue int64, fraction bool) *progressbar.ProgressBar {
+	opts := []progressbar.Option{
+		progressbar.OptionSetWriter(tp.w),
+		progressbar.OptionSetDescription(tp.desc),
+		progressbar.OptionClearOnFinish(),
+	}
+
+	if !fraction {
+		opts = append(opts, progressbar.OptionShowCount())
+	}
+
+	if maxValue != -1 {
+		opts = append(opts, progressbar.OptionSetPredictTime(true))
+	}
+
+	return progressbar.NewOptions64(maxValue, opts...)
+}
+
+func (tp *taskProgress) clear() {
+	if tp.bar != nil {
+		_ = tp.bar.Clear()
+		tp.bar = nil
+	}
+}
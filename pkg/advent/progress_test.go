@@ -0,0 +1,40 @@
+package advent
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/asphaltbuffet/elf/pkg/runners"
+)
+
+func Test_trackProgress(t *testing.T) {
+	tests := []struct {
+		name     string
+		progress []runners.Progress
+		want     string
+	}{
+		{"no progress", nil, ""},
+		{"counted", []runners.Progress{{Done: 1, Total: 4}, {Done: 2, Total: 4}}, "(2/4)"},
+		{"fraction", []runners.Progress{{Fraction: 0.5}}, "50%"},
+		{"unknown total", []runners.Progress{{Done: 7}}, "(7/-)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var b bytes.Buffer
+
+			task := &runners.Task{TaskID: "solve.1"}
+			clearProgress := trackProgress(&b, task)
+
+			for _, p := range tt.progress {
+				task.OnProgress(p)
+			}
+
+			assert.Contains(t, b.String(), tt.want)
+
+			clearProgress()
+		})
+	}
+}
@@ -78,7 +78,10 @@ func (e *Exercise) runMainTasks() ([]tasks.Result, error) {
 	results := make([]tasks.Result, 0, len(solveTasks))
 
 	for _, t := range solveTasks {
+		clearProgress := trackProgress(e.writer, t.task)
 		result, err := e.runner.Run(t.task)
+		clearProgress()
+
 		if err != nil {
 			return nil, err
 		}
@@ -105,6 +108,7 @@ func makeMainTasks(part runners.Part, data *Data) []testTask {
 			TaskID:    tasks.MakeTaskID(tasks.Solve, part),
 			Part:      part,
 			Input:     data.InputData,
+			InputID:   runners.InputID(data.InputData), // both parts share the parsed input
 			OutputDir: "",
 		},
 		expected: expected,
@@ -118,11 +122,12 @@ func handleTaskResult(w io.Writer, r *runners.Result, expected string) tasks.Res
 	taskType, part, subpart := tasks.ParseTaskID(r.TaskID)
 
 	result := tasks.Result{
-		ID:       r.TaskID,
-		Type:     taskType,
-		Part:     part,
-		SubPart:  subpart,
-		Duration: r.Duration,
+		ID:            r.TaskID,
+		Type:          taskType,
+		Part:          part,
+		SubPart:       subpart,
+		Duration:      r.Duration,
+		ParseDuration: r.ParseDuration,
 	}
 
 	dur, err := time.ParseDuration(fmt.Sprintf("%fs", r.Duration)) // TODO: store duration as time.Duration
@@ -145,13 +150,19 @@ func handleTaskResult(w io.Writer, r *runners.Result, expected string) tasks.Res
 	case !r.Ok:
 		result.Status = tasks.StatusError
 		result.Output = fmt.Sprint("⤷ saying:", r.Output)
+		result.Trace = r.Trace
 
 		output = lipgloss.NewStyle().
 			Bold(true).Align(lipgloss.Center).
 			Foreground(lipgloss.Color("9")).
 			SetString("ERROR")
 
-		extra = extraStyle.Foreground(bad).SetString("⤷ saying: " + r.Output)
+		saying := "⤷ saying: " + r.Output
+		if r.Trace != "" {
+			saying += "\n" + r.Trace
+		}
+
+		extra = extraStyle.Foreground(bad).SetString(saying)
 		printExtra = true
 
 	case expected == "":
@@ -192,7 +203,15 @@ func handleTaskResult(w io.Writer, r *runners.Result, expected string) tasks.Res
 	}
 
 	if taskType != tasks.Benchmark {
-		fmt.Fprintln(w, name, output, followUpText)
+		line := []any{name, output, followUpText}
+
+		// parsing is timed separately from solving
+		if r.ParseDuration > 0 && followUpText.Value() != "" {
+			parseDur := time.Duration(r.ParseDuration * float64(time.Second))
+			line = append(line, parseStyle.SetString("+ "+parseDur.String()+" parse"))
+		}
+
+		fmt.Fprintln(w, line...)
 
 		// show extra info
 		if printExtra {
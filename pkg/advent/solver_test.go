@@ -101,6 +101,51 @@ func Test_handleMainResult(t *testing.T) {
 				Duration: 0.042,
 			},
 		},
+		{
+			name: "parsed input",
+			args: args{
+				r: &runners.Result{
+					TaskID:        "solve.1",
+					Ok:            true,
+					Output:        "good output",
+					Duration:      0.042,
+					ParseDuration: 0.01,
+				},
+			},
+			want: tasks.Result{
+				ID:            "solve.1",
+				Type:          tasks.Solve,
+				Part:          1,
+				Status:        tasks.StatusPassed,
+				Output:        "good output",
+				Expected:      "good output",
+				Duration:      0.042,
+				ParseDuration: 0.01,
+			},
+		},
+		{
+			name: "panic with trace",
+			args: args{
+				r: &runners.Result{
+					TaskID:   "test.1.2",
+					Ok:       false,
+					Output:   "panic: boom",
+					Trace:    "exercises.Exercise.One\n\t/exercises/go/exercise.go:12",
+					Duration: 0.042,
+				},
+			},
+			want: tasks.Result{
+				ID:       "test.1.2",
+				Type:     tasks.Test,
+				Part:     1,
+				SubPart:  2,
+				Status:   tasks.StatusError,
+				Output:   "⤷ saying:panic: boom",
+				Trace:    "exercises.Exercise.One\n\t/exercises/go/exercise.go:12",
+				Expected: "",
+				Duration: 0.042,
+			},
+		},
 	}
 
 	for _, tt := range tests {
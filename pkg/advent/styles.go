@@ -29,6 +29,7 @@ var (
 	statusStyle = lipgloss.NewStyle().Bold(true).Width(StatusWidth)
 	extraStyle  = lipgloss.NewStyle().Italic(true).PaddingLeft(ExtraPadding)
 	timeStyle   = lipgloss.NewStyle().Faint(true).Italic(true).Foreground(minor).Width(TimeWidth).Align(lipgloss.Right)
+	parseStyle  = lipgloss.NewStyle().Faint(true).Italic(true).Foreground(minor)
 )
 
 func headerStyle(s string) lipgloss.Style {
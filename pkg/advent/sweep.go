@@ -0,0 +1,449 @@
+package advent
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/lipgloss/table"
+	"github.com/dustin/go-humanize"
+	"github.com/lmittmann/tint"
+	"github.com/spf13/afero"
+
+	"github.com/asphaltbuffet/elf/pkg/krampus"
+	"github.com/asphaltbuffet/elf/pkg/runners"
+)
+
+// SweepStatus is the outcome of benchmarking a single exercise during a sweep.
+type SweepStatus string
+
+const (
+	SweepBenchmarked SweepStatus = "benchmarked"
+	SweepSkipped     SweepStatus = "up to date"
+	SweepFailed      SweepStatus = "failed"
+)
+
+// Sweep benchmarks every exercise found under a directory.
+type Sweep struct {
+	Dir   string
+	Force bool
+
+	config    krampus.ExerciseConfiguration
+	benchOpts []func(*Benchmarker)
+	appFs     afero.Fs
+	logger    *slog.Logger
+	writer    io.Writer
+}
+
+// SweepResult holds the benchmark summary for one exercise in a sweep.
+type SweepResult struct {
+	Path   string
+	Year   int
+	Day    int
+	Title  string
+	Status SweepStatus
+	Err    error
+
+	// Times maps an implementation name to the combined mean running time of all parts.
+	Times map[string]float64
+}
+
+func NewSweep(config krampus.ExerciseConfiguration, options ...func(*Sweep)) (*Sweep, error) {
+	s := &Sweep{
+		config: config,
+		appFs:  config.GetFs(),
+		logger: config.GetLogger().With(slog.String("fn", "sweep")),
+		writer: os.Stdout,
+	}
+
+	for _, option := range options {
+		option(s)
+	}
+
+	if s.Dir == "" {
+		return nil, fmt.Errorf("sweep directory: %w", ErrNotFound)
+	}
+
+	return s, nil
+}
+
+// WithSweepDir sets the root directory to search for exercises. This may be a year
+// directory or the root of the exercise tree.
+func WithSweepDir(dir string) func(*Sweep) {
+	return func(s *Sweep) {
+		s.Dir = dir
+	}
+}
+
+// WithForce benchmarks every exercise, even if its benchmark data is up to date.
+func WithForce(force bool) func(*Sweep) {
+	return func(s *Sweep) {
+		s.Force = force
+	}
+}
+
+// WithBenchmarkOptions sets options applied to the benchmarker of each exercise.
+func WithBenchmarkOptions(opts ...func(*Benchmarker)) func(*Sweep) {
+	return func(s *Sweep) {
+		s.benchOpts = opts
+	}
+}
+
+// Run benchmarks each exercise under the sweep directory with the given number of iterations.
+//
+// Exercises that fail are reported in the results and do not stop the sweep.
+func (s *Sweep) Run(iterations int) ([]SweepResult, error) {
+	dirs, err := FindExercises(s.appFs, s.Dir)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(dirs) == 0 {
+		return nil, fmt.Errorf("search %s: %w", s.Dir, ErrNotFound)
+	}
+
+	results := make([]SweepResult, 0, len(dirs))
+
+	for i, dir := range dirs {
+		progress := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("5")).
+			SetString(fmt.Sprintf("[%*d/%d]", len(strconv.Itoa(len(dirs))), i+1, len(dirs)))
+
+		result := s.benchmarkExercise(dir, iterations)
+		results = append(results, result)
+
+		switch result.Status {
+		case SweepFailed:
+			fmt.Fprintln(s.writer, progress, dir, extraStyle.Foreground(bad).SetString(result.Err.Error()))
+		default:
+			fmt.Fprintln(s.writer, progress, dir, timeStyle.SetString(string(result.Status)))
+		}
+	}
+
+	return results, nil
+}
+
+func (s *Sweep) benchmarkExercise(dir string, iterations int) SweepResult {
+	logger := s.logger.With(slog.String("dir", dir))
+	result := SweepResult{Path: dir}
+
+	opts := append([]func(*Benchmarker){WithExerciseDir(dir)}, s.benchOpts...)
+
+	b, err := NewBenchmarker(s.config, opts...)
+	if err != nil {
+		logger.Error("loading exercise", tint.Err(err))
+
+		result.Status, result.Err = SweepFailed, err
+
+		return result
+	}
+
+	stale := s.Force
+	if !stale {
+		if stale, err = b.NeedsBenchmark(s.appFs); err != nil {
+			logger.Error("checking benchmark data", tint.Err(err))
+
+			result.Status, result.Err = SweepFailed, err
+
+			return result
+		}
+	}
+
+	if stale {
+		if _, err = b.Benchmark(s.appFs, iterations); err != nil {
+			logger.Error("benchmarking exercise", tint.Err(err))
+
+			result.Status, result.Err = SweepFailed, err
+
+			return result
+		}
+
+		result.Status = SweepBenchmarked
+	} else {
+		result.Status = SweepSkipped
+	}
+
+	data, err := readBenchmarkData(s.appFs, b.benchmarkFile())
+	if err != nil {
+		result.Status, result.Err = SweepFailed, err
+		return result
+	}
+
+	local, _ := splitByMachine(data, CurrentMachine())
+
+	if latest := latestBenchmarkData(local); latest != nil {
+		result.Year = latest.Year
+		result.Day = latest.Day
+		result.Title = latest.Title
+		result.Times = implementationTimes(latest.Implementations)
+	}
+
+	return result
+}
+
+func implementationTimes(impls []*ImplementationData) map[string]float64 {
+	times := make(map[string]float64, len(impls))
+
+	for _, impl := range impls {
+		if impl == nil {
+			continue
+		}
+
+		var total float64
+
+		for _, p := range []*PartData{impl.PartOne, impl.PartTwo} {
+			if p != nil {
+				total += p.Mean
+			}
+		}
+
+		times[impl.Name] = total
+	}
+
+	return times
+}
+
+// FindExercises returns all exercise directories (those with an info file) under root, in
+// lexical order.
+func FindExercises(afs afero.Fs, root string) ([]string, error) {
+	var dirs []string
+
+	err := afero.Walk(afs, root, func(path string, info fs.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if !info.IsDir() && info.Name() == "info.json" {
+			dirs = append(dirs, filepath.Dir(path))
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("search %s: %w", root, err)
+	}
+
+	slices.Sort(dirs)
+
+	return dirs, nil
+}
+
+// NeedsBenchmark reports whether any selected implementation and part of the exercise has no
+// benchmark data from this machine, or has changed since it was last benchmarked. Only the
+// files used by an implementation are compared: its own directory and the files shared by
+// all implementations.
+func (b *Benchmarker) NeedsBenchmark(afs afero.Fs) (bool, error) {
+	outfile := b.benchmarkFile()
+
+	bench, err := afs.Stat(outfile)
+	if errors.Is(err, os.ErrNotExist) {
+		return true, nil
+	}
+
+	if err != nil {
+		return false, err
+	}
+
+	data, err := readBenchmarkData(afs, outfile)
+	if err != nil {
+		return false, err
+	}
+
+	local, _ := splitByMachine(data, CurrentMachine())
+
+	latest := latestBenchmarkData(local)
+	if latest == nil {
+		return true, nil
+	}
+
+	impls, err := b.selectImplementations()
+	if err != nil {
+		return false, err
+	}
+
+	for _, impl := range impls {
+		implData := findImplementationData(latest.Implementations, impl)
+		if implData == nil {
+			return true, nil
+		}
+
+		changed, err := lastChange(afs, b.Path, implementationLanguage(impl))
+		if err != nil {
+			return false, err
+		}
+
+		for _, part := range b.benchmarkParts() {
+			partData := implData.PartOne
+			if part == runners.PartTwo {
+				partData = implData.PartTwo
+			}
+
+			if partData == nil {
+				return true, nil
+			}
+
+			// data from before parts were dated is as old as the benchmark file
+			ran := partData.Date
+			if ran.IsZero() {
+				ran = bench.ModTime()
+			}
+
+			if changed.After(ran) {
+				return true, nil
+			}
+		}
+	}
+
+	return false, nil
+}
+
+// findImplementationData returns the benchmark data of an implementation, or nil if there is
+// none. Data saved without a runner key is matched by the name of the runner.
+func findImplementationData(impls []*ImplementationData, impl string) *ImplementationData {
+	name, _ := runners.NameOf(impl)
+
+	for _, data := range impls {
+		if data != nil && (data.Runner == impl || (data.Runner == "" && name != "" && data.Name == name)) {
+			return data
+		}
+	}
+
+	return nil
+}
+
+// implementationLanguage returns the language of an implementation, which is the runner of a
+// variant.
+func implementationLanguage(impl string) string {
+	if v, ok := runners.LookupVariant(impl); ok {
+		return v.Runner
+	}
+
+	return impl
+}
+
+// lastChange returns the latest modification time of the files in an exercise that affect
+// benchmarks of an implementation in lang. Directories of other languages, visualization
+// output, and files that don't affect results are left out.
+func lastChange(afs afero.Fs, dir, lang string) (time.Time, error) {
+	var latest time.Time
+
+	dir = filepath.Clean(dir)
+
+	err := afero.Walk(afs, dir, func(path string, info fs.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if info.IsDir() {
+			if filepath.Dir(path) != dir {
+				return nil
+			}
+
+			if dirLang, ok := runners.LanguageForDir(info.Name()); info.Name() == visualizationDirName || info.Name() == inputBenchmarkDirName || (ok && dirLang != lang) {
+				return filepath.SkipDir
+			}
+
+			return nil
+		}
+
+		switch {
+		case info.Name() == benchmarkFileName || info.Name() == "README.md":
+			// these don't affect benchmark results
+		case info.ModTime().After(latest):
+			latest = info.ModTime()
+		}
+
+		return nil
+	})
+
+	return latest, err
+}
+
+// WriteSweepSummary writes a table of running time per exercise and implementation, followed
+// by the total running time of each implementation in each year and overall. Year subtotals
+// are left out when all exercises are from the same year.
+func WriteSweepSummary(w io.Writer, results []SweepResult) {
+	var (
+		impls []string
+		years []int
+	)
+
+	totals := map[string]float64{}
+	yearTotals := map[int]map[string]float64{}
+
+	for _, r := range results {
+		if r.Year != 0 && yearTotals[r.Year] == nil {
+			years = append(years, r.Year)
+			yearTotals[r.Year] = map[string]float64{}
+		}
+
+		for name, t := range r.Times {
+			if !slices.Contains(impls, name) {
+				impls = append(impls, name)
+			}
+
+			totals[name] += t
+
+			if r.Year != 0 {
+				yearTotals[r.Year][name] += t
+			}
+		}
+	}
+
+	slices.Sort(impls)
+	slices.Sort(years)
+
+	t := table.New().
+		Border(lipgloss.NormalBorder()).
+		Headers(append([]string{"Exercise", "Status"}, impls...)...)
+
+	for _, r := range results {
+		name := r.Path
+		if r.Day != 0 {
+			name = fmt.Sprintf("%d-%02d %s", r.Year, r.Day, r.Title)
+		}
+
+		row := []string{name, string(r.Status)}
+
+		for _, impl := range impls {
+			row = append(row, formatSweepTime(r.Times, impl))
+		}
+
+		t.Row(row...)
+	}
+
+	totalRow := func(label string, times map[string]float64) {
+		row := []string{label, ""}
+		for _, impl := range impls {
+			row = append(row, formatSweepTime(times, impl))
+		}
+
+		t.Row(row...)
+	}
+
+	if len(years) > 1 {
+		for _, year := range years {
+			totalRow(fmt.Sprintf("%d Total", year), yearTotals[year])
+		}
+	}
+
+	totalRow("Total", totals)
+
+	fmt.Fprintln(w, t)
+}
+
+func formatSweepTime(times map[string]float64, impl string) string {
+	t, ok := times[impl]
+	if !ok {
+		return "-"
+	}
+
+	return humanize.SIWithDigits(t, 2, "s") //nolint:mnd // display precision
+}
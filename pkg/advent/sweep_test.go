@@ -0,0 +1,301 @@
+package advent
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	krampusMocks "github.com/asphaltbuffet/elf/mocks/krampus"
+	"github.com/asphaltbuffet/elf/pkg/runners"
+)
+
+func TestNewSweep(t *testing.T) {
+	tests := []struct {
+		name      string
+		options   []func(*Sweep)
+		wantDir   string
+		assertion require.ErrorAssertionFunc
+	}{
+		{
+			name:      "no directory",
+			options:   nil,
+			assertion: require.Error,
+		},
+		{
+			name:      "with directory",
+			options:   []func(*Sweep){WithSweepDir("exercises/2017"), WithForce(true)},
+			wantDir:   "exercises/2017",
+			assertion: require.NoError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockConfig := krampusMocks.NewMockExerciseConfiguration(t)
+			mockConfig.EXPECT().GetFs().Return(afero.NewMemMapFs())
+			mockConfig.EXPECT().GetLogger().Return(slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+			got, err := NewSweep(mockConfig, tt.options...)
+
+			tt.assertion(t, err)
+			if err == nil {
+				assert.Equal(t, tt.wantDir, got.Dir)
+				assert.True(t, got.Force)
+			}
+		})
+	}
+}
+
+func TestFindExercises(t *testing.T) {
+	teardownTestCase := setupTestCase(t)
+	defer teardownTestCase(t)
+
+	teardownSubTest := setupSubTest(t)
+	defer teardownSubTest(t)
+
+	got, err := FindExercises(testFs, "exercises")
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{
+		filepath.Join("exercises", "2017", "01-fakeFullDay"),
+		filepath.Join("exercises", "2017", "03-fakeGoDay"),
+	}, got)
+
+	_, err = FindExercises(testFs, "missing")
+	require.Error(t, err)
+}
+
+func TestBenchmarker_NeedsBenchmark(t *testing.T) {
+	const dir = "exercises/2023/01-fake"
+
+	benchTime := time.Date(2023, time.December, 2, 0, 0, 0, 0, time.UTC)
+	before, after := benchTime.Add(-time.Hour), benchTime.Add(time.Hour)
+
+	sources := func(changed ...string) map[string]time.Time {
+		files := map[string]time.Time{
+			"go/exercise.go": before,
+			"py/exercise.py": before,
+			"input.txt":      before,
+		}
+
+		for _, name := range changed {
+			files[name] = after
+		}
+
+		return files
+	}
+
+	dated := func() *PartData { return &PartData{Mean: 1, Date: benchTime} }
+	goData := func(one, two *PartData) *ImplementationData {
+		return &ImplementationData{Name: "Go", Runner: "go", PartOne: one, PartTwo: two}
+	}
+	pyData := &ImplementationData{Name: "Python", Runner: "py", PartOne: dated(), PartTwo: dated()}
+
+	tests := []struct {
+		name    string
+		sources map[string]time.Time
+		data    []BenchmarkData // nil for no benchmark file
+		impls   []string
+		part    runners.Part
+		want    bool
+	}{
+		{
+			name:    "no benchmark data",
+			sources: sources(),
+			want:    true,
+		},
+		{
+			name:    "up to date",
+			sources: sources(),
+			data:    []BenchmarkData{{Implementations: []*ImplementationData{goData(dated(), dated()), pyData}}},
+			want:    false,
+		},
+		{
+			name:    "source changed",
+			sources: sources("go/exercise.go"),
+			data:    []BenchmarkData{{Implementations: []*ImplementationData{goData(dated(), dated()), pyData}}},
+			want:    true,
+		},
+		{
+			name:    "shared file changed",
+			sources: sources("input.txt"),
+			data:    []BenchmarkData{{Implementations: []*ImplementationData{goData(dated(), dated()), pyData}}},
+			impls:   []string{"go"},
+			want:    true,
+		},
+		{
+			name:    "other language changed",
+			sources: sources("py/exercise.py"),
+			data:    []BenchmarkData{{Implementations: []*ImplementationData{goData(dated(), dated()), pyData}}},
+			impls:   []string{"go"},
+			want:    false,
+		},
+		{
+			name:    "readme, visualization, and results for other inputs changed",
+			sources: sources("README.md", "vis/go/frame.svg", "benchmark-inputs/alt-input.json"),
+			data:    []BenchmarkData{{Implementations: []*ImplementationData{goData(dated(), dated()), pyData}}},
+			want:    false,
+		},
+		{
+			name:    "other json file changed",
+			sources: sources("benchmark-notes.json"),
+			data:    []BenchmarkData{{Implementations: []*ImplementationData{goData(dated(), dated()), pyData}}},
+			want:    true,
+		},
+		{
+			name:    "implementation missing",
+			sources: sources(),
+			data:    []BenchmarkData{{Implementations: []*ImplementationData{goData(dated(), dated())}}},
+			want:    true,
+		},
+		{
+			name:    "other implementation missing",
+			sources: sources(),
+			data:    []BenchmarkData{{Implementations: []*ImplementationData{goData(dated(), dated())}}},
+			impls:   []string{"go"},
+			want:    false,
+		},
+		{
+			name:    "part missing",
+			sources: sources(),
+			data:    []BenchmarkData{{Implementations: []*ImplementationData{goData(dated(), nil), pyData}}},
+			want:    true,
+		},
+		{
+			name:    "other part missing",
+			sources: sources(),
+			data:    []BenchmarkData{{Implementations: []*ImplementationData{goData(dated(), nil), pyData}}},
+			part:    runners.PartOne,
+			want:    false,
+		},
+		{
+			name:    "part older than source",
+			sources: sources("go/exercise.go"),
+			data: []BenchmarkData{{Implementations: []*ImplementationData{
+				goData(dated(), &PartData{Mean: 1, Date: after.Add(time.Hour)}), pyData,
+			}}},
+			part: runners.PartOne,
+			want: true,
+		},
+		{
+			name:    "undated data matched by name",
+			sources: sources(),
+			data: []BenchmarkData{{Implementations: []*ImplementationData{
+				{Name: "Go", PartOne: &PartData{Mean: 1}, PartTwo: &PartData{Mean: 1}}, pyData,
+			}}},
+			want: false,
+		},
+		{
+			name:    "data from another machine",
+			sources: sources(),
+			data: []BenchmarkData{{
+				Machine:         &Machine{Host: "elsewhere"},
+				Implementations: []*ImplementationData{goData(dated(), dated()), pyData},
+			}},
+			want: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			afs := afero.NewMemMapFs()
+
+			for name, mod := range tt.sources {
+				fp := filepath.Join(dir, name)
+				require.NoError(t, afero.WriteFile(afs, fp, []byte("fake"), 0o600))
+				require.NoError(t, afs.Chtimes(fp, mod, mod))
+			}
+
+			if tt.data != nil {
+				raw, err := json.Marshal(tt.data)
+				require.NoError(t, err)
+
+				fp := filepath.Join(dir, "benchmark.json")
+				require.NoError(t, afero.WriteFile(afs, fp, raw, 0o600))
+				require.NoError(t, afs.Chtimes(fp, benchTime, benchTime))
+			}
+
+			// variants registered by other tests would be selected along with all languages
+			impls := tt.impls
+			if impls == nil {
+				impls = []string{"go", "py"}
+			}
+
+			b := &Benchmarker{
+				Exercise: &Exercise{Path: dir, appFs: afs},
+				impls:    impls,
+				part:     tt.part,
+			}
+
+			got, err := b.NeedsBenchmark(afs)
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestImplementationTimes(t *testing.T) {
+	got := implementationTimes([]*ImplementationData{
+		{Name: "Go", PartOne: &PartData{Mean: 1}, PartTwo: &PartData{Mean: 2}},
+		{Name: "Python", PartOne: nil, PartTwo: &PartData{Mean: 5}},
+		nil,
+	})
+
+	assert.Equal(t, map[string]float64{"Go": 3, "Python": 5}, got)
+}
+
+func TestWriteSweepSummary(t *testing.T) {
+	results := []SweepResult{
+		{
+			Path: "exercises/2023/01-fake", Year: 2023, Day: 1, Title: "Fake", Status: SweepSkipped,
+			Times: map[string]float64{"Go": 0.001, "Python": 0.5},
+		},
+		{
+			Path: "exercises/2023/02-fake", Year: 2023, Day: 2, Title: "Fake Two", Status: SweepBenchmarked,
+			Times: map[string]float64{"Go": 0.002},
+		},
+		{
+			Path: "exercises/2023/03-broken", Status: SweepFailed,
+		},
+	}
+
+	t.Run("one year", func(t *testing.T) {
+		var buf bytes.Buffer
+
+		WriteSweepSummary(&buf, results)
+
+		got := buf.String()
+
+		assert.Contains(t, got, "2023-01 Fake")
+		assert.Contains(t, got, "2023-02 Fake Two")
+		assert.Contains(t, got, "exercises/2023/03-broken")
+		assert.Contains(t, got, "Total")
+		assert.NotContains(t, got, "2023 Total")
+		assert.Contains(t, got, "3 ms")
+		assert.Contains(t, got, "500 ms")
+	})
+
+	t.Run("several years", func(t *testing.T) {
+		var buf bytes.Buffer
+
+		WriteSweepSummary(&buf, append([]SweepResult{{
+			Path: "exercises/2022/01-old", Year: 2022, Day: 1, Title: "Old", Status: SweepSkipped,
+			Times: map[string]float64{"Go": 0.004},
+		}}, results...))
+
+		got := buf.String()
+
+		assert.Regexp(t, `│2022 Total\s+│\s+│4 ms│-\s+│`, got)
+		assert.Regexp(t, `│2023 Total\s+│\s+│3 ms│500 ms│`, got)
+		assert.Regexp(t, `│Total\s+│\s+│7 ms│500 ms│`, got)
+	})
+}
@@ -59,7 +59,10 @@ func (e *Exercise) runTests() ([]tasks.Result, error) {
 	results := make([]tasks.Result, 0, len(testTasks))
 
 	for _, t := range testTasks {
+		clearProgress := trackProgress(e.writer, t.task)
 		result, err := e.runner.Run(t.task)
+		clearProgress()
+
 		if err != nil {
 			e.logger.Error("running test task", tint.Err(err))
 			return nil, err
@@ -81,6 +84,7 @@ func makeTestTasks(p runners.Part, tests []*Test) []testTask {
 				TaskID:    tasks.MakeTaskID(tasks.Test, p, i),
 				Part:      p,
 				Input:     t.Input,
+				InputID:   runners.InputID(t.Input),
 				OutputDir: "",
 			},
 			expected: t.Expected,
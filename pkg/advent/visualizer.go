@@ -0,0 +1,171 @@
+package advent
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/dustin/go-humanize"
+	"github.com/lmittmann/tint"
+	"github.com/spf13/afero"
+
+	"github.com/asphaltbuffet/elf/pkg/runners"
+	"github.com/asphaltbuffet/elf/pkg/tasks"
+)
+
+// visualizationDirName is the directory in an exercise that holds the output of visualizations,
+// with a subdirectory for each implementation.
+const visualizationDirName = "vis"
+
+// ErrVisualizationFailed is returned when the visualization of an exercise fails.
+var ErrVisualizationFailed = errors.New("visualization failed")
+
+// VisualizationDir returns the directory managed by elf for the output of the visualization
+// of the exercise's implementation.
+func (e *Exercise) VisualizationDir() string {
+	return filepath.Join(e.Path, visualizationDirName, e.Language)
+}
+
+// Visualize runs the visualization of the exercise with its input. The output is written to
+// outputDir, or to VisualizationDir if it is empty, which is cleared first. It returns the
+// files written or changed by the visualization, relative to the output directory.
+func (e *Exercise) Visualize(outputDir string) ([]string, error) {
+	logger := e.logger.With(slog.String("exercise", e.Title))
+	logger.Debug("visualizing", slog.String("language", e.Language))
+
+	inputFile := filepath.Join(e.Path, e.Data.InputFileName)
+	input, err := afero.ReadFile(e.appFs, inputFile)
+	if err != nil {
+		logger.Error("reading input file", slog.String("path", inputFile), tint.Err(err))
+		return nil, err
+	}
+
+	e.Data.InputData = string(input)
+
+	if outputDir == "" {
+		outputDir = e.VisualizationDir()
+
+		// only output of the last visualization is kept in the managed directory
+		if err = e.appFs.RemoveAll(outputDir); err != nil {
+			return nil, fmt.Errorf("clearing output directory: %w", err)
+		}
+	}
+
+	if outputDir, err = filepath.Abs(outputDir); err != nil {
+		return nil, err
+	}
+
+	if err = e.appFs.MkdirAll(outputDir, 0o750); err != nil {
+		return nil, fmt.Errorf("creating output directory: %w", err)
+	}
+
+	// files already in a custom output directory are only reported if they change
+	existing, err := e.visualizationFiles(outputDir)
+	if err != nil {
+		return nil, fmt.Errorf("listing output directory: %w", err)
+	}
+
+	runners.AllowWrite(e.runner, outputDir)
+
+	if err = e.runner.Start(); err != nil {
+		logger.Error("starting runner", tint.Err(err))
+		return nil, err
+	}
+
+	defer func() {
+		_ = e.runner.Stop()
+		_ = e.runner.Cleanup()
+	}()
+
+	fmt.Fprintln(e.writer, headerStyle(fmt.Sprintf("ADVENT OF CODE %d\nDay %d: %s", e.Year, e.Day, e.Title)))
+	fmt.Fprintf(e.writer, "Visualizing (%s)...\n", e.runner)
+
+	task := &runners.Task{
+		TaskID:    tasks.MakeTaskID(tasks.Visualize, runners.Visualize, 0),
+		Part:      runners.Visualize,
+		Input:     e.Data.InputData,
+		OutputDir: outputDir,
+	}
+
+	clearProgress := trackProgress(e.writer, task)
+	result, err := e.runner.Run(task)
+	clearProgress()
+
+	if err != nil {
+		logger.Error("running visualization", tint.Err(err))
+		return nil, err
+	}
+
+	if !result.Ok {
+		handleTaskResult(e.writer, result, "")
+		return nil, fmt.Errorf("%w: %s", ErrVisualizationFailed, result.Output)
+	}
+
+	files, err := e.visualizationFiles(outputDir)
+	if err != nil {
+		return nil, fmt.Errorf("listing output: %w", err)
+	}
+
+	files = slices.DeleteFunc(files, func(f visualizationFile) bool {
+		return slices.ContainsFunc(existing, f.unchanged)
+	})
+
+	if len(files) == 0 {
+		fmt.Fprintln(e.writer, extraStyle.Foreground(minor).SetString("⤷ no files written to "+outputDir))
+	} else {
+		fmt.Fprintf(e.writer, "Wrote %d file(s) to %s\n", len(files), outputDir)
+	}
+
+	names := make([]string, 0, len(files))
+
+	for _, f := range files {
+		size := parseStyle.SetString(humanize.IBytes(uint64(f.size)))
+		fmt.Fprintln(e.writer, extraStyle.SetString("⤷ "+f.name), size)
+
+		names = append(names, f.name)
+	}
+
+	return names, nil
+}
+
+type visualizationFile struct {
+	name    string
+	size    int64
+	modTime time.Time
+}
+
+// unchanged reports whether the file is the same as before, going by its size and
+// modification time.
+func (f visualizationFile) unchanged(before visualizationFile) bool {
+	return f.name == before.name && f.size == before.size && f.modTime.Equal(before.modTime)
+}
+
+// visualizationFiles lists the files in the output directory of a visualization.
+func (e *Exercise) visualizationFiles(outputDir string) ([]visualizationFile, error) {
+	var files []visualizationFile
+
+	err := afero.Walk(e.appFs, outputDir, func(path string, info fs.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if info.IsDir() {
+			return nil
+		}
+
+		name, err := filepath.Rel(outputDir, path)
+		if err != nil {
+			return err
+		}
+
+		files = append(files, visualizationFile{name: name, size: info.Size(), modTime: info.ModTime()})
+
+		return nil
+	})
+
+	return files, err
+}
@@ -0,0 +1,136 @@
+package advent
+
+import (
+	"errors"
+	"io"
+	"log/slog"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	mocks "github.com/asphaltbuffet/elf/mocks/runners"
+	"github.com/asphaltbuffet/elf/pkg/runners"
+)
+
+func TestExercise_Visualize(t *testing.T) {
+	const exerciseDir = "/exercises/2017/01-fakeFullDay"
+
+	managedDir := filepath.Join(exerciseDir, "vis", "go")
+
+	tests := []struct {
+		name      string
+		outputDir string
+		run       func(fs afero.Fs, task *runners.Task) (*runners.Result, error)
+		want      []string
+		wantDir   string
+		assertion require.ErrorAssertionFunc
+	}{
+		{
+			name: "managed directory",
+			run: func(fs afero.Fs, task *runners.Task) (*runners.Result, error) {
+				_ = afero.WriteFile(fs, filepath.Join(task.OutputDir, "frames", "001.txt"), []byte("frame"), 0o600)
+				_ = afero.WriteFile(fs, filepath.Join(task.OutputDir, "summary.txt"), []byte(task.Input), 0o600)
+
+				return &runners.Result{TaskID: task.TaskID, Ok: true}, nil
+			},
+			want:      []string{filepath.Join("frames", "001.txt"), "summary.txt"},
+			wantDir:   managedDir,
+			assertion: require.NoError,
+		},
+		{
+			name:      "custom directory",
+			outputDir: "/tmp/frames",
+			run: func(fs afero.Fs, task *runners.Task) (*runners.Result, error) {
+				_ = afero.WriteFile(fs, filepath.Join(task.OutputDir, "out.svg"), []byte("<svg/>"), 0o600)
+
+				return &runners.Result{TaskID: task.TaskID, Ok: true}, nil
+			},
+			want:      []string{"out.svg"}, // existing files are kept but not reported
+			wantDir:   "/tmp/frames",
+			assertion: require.NoError,
+		},
+		{
+			name:      "custom directory with rewritten file",
+			outputDir: "/tmp/frames",
+			run: func(fs afero.Fs, task *runners.Task) (*runners.Result, error) {
+				_ = afero.WriteFile(fs, filepath.Join(task.OutputDir, "stale.txt"), []byte("rewritten"), 0o600)
+
+				return &runners.Result{TaskID: task.TaskID, Ok: true}, nil
+			},
+			want:      []string{"stale.txt"},
+			wantDir:   "/tmp/frames",
+			assertion: require.NoError,
+		},
+		{
+			name: "no files",
+			run: func(_ afero.Fs, task *runners.Task) (*runners.Result, error) {
+				return &runners.Result{TaskID: task.TaskID, Ok: true}, nil
+			},
+			want:      []string{},
+			wantDir:   managedDir,
+			assertion: require.NoError,
+		},
+		{
+			name: "visualization fails",
+			run: func(_ afero.Fs, task *runners.Task) (*runners.Result, error) {
+				return &runners.Result{TaskID: task.TaskID, Ok: false, Output: "visualization not implemented"}, nil
+			},
+			wantDir: managedDir,
+			assertion: func(t require.TestingT, err error, _ ...interface{}) {
+				require.ErrorIs(t, err, ErrVisualizationFailed)
+			},
+		},
+		{
+			name: "runner error",
+			run: func(_ afero.Fs, _ *runners.Task) (*runners.Result, error) {
+				return nil, errors.New("FAKE ERROR")
+			},
+			wantDir:   managedDir,
+			assertion: require.Error,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fs := afero.NewMemMapFs()
+			require.NoError(t, afero.WriteFile(fs, filepath.Join(exerciseDir, "input.txt"), []byte("FAKE INPUT"), 0o600))
+			require.NoError(t, afero.WriteFile(fs, filepath.Join(managedDir, "stale.txt"), []byte("old"), 0o600))
+			require.NoError(t, afero.WriteFile(fs, "/tmp/frames/stale.txt", []byte("old"), 0o600))
+
+			mockRunner := mocks.NewMockRunner(t)
+			mockRunner.EXPECT().Start().Return(nil).Once()
+			mockRunner.EXPECT().Stop().Return(nil).Once()
+			mockRunner.EXPECT().Cleanup().Return(nil).Once()
+			mockRunner.EXPECT().String().Return("Go").Maybe()
+			mockRunner.EXPECT().Run(mock.Anything).RunAndReturn(func(task *runners.Task) (*runners.Result, error) {
+				assert.Equal(t, runners.Visualize, task.Part)
+				assert.Equal(t, "FAKE INPUT", task.Input)
+				assert.Equal(t, tt.wantDir, task.OutputDir)
+
+				return tt.run(fs, task)
+			}).Once()
+
+			e := &Exercise{
+				Title:    "Fake Full Day",
+				Language: "go",
+				Year:     2017,
+				Day:      1,
+				Path:     exerciseDir,
+				Data:     &Data{InputFileName: "input.txt"},
+				runner:   mockRunner,
+				appFs:    fs,
+				logger:   slog.New(slog.NewTextHandler(io.Discard, nil)),
+				writer:   io.Discard,
+			}
+
+			got, err := e.Visualize(tt.outputDir)
+
+			tt.assertion(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -3,7 +3,9 @@ package analysis
 import "strings"
 
 type Analyzer interface {
+	Export() error
 	Graph(GraphType) error
+	Report() error
 	Stats() error
 }
 
@@ -14,6 +16,10 @@ const (
 	Invalid GraphType = iota
 	Line
 	Box
+	Heatmap
+	Histogram
+	StackedBar
+	Scatter
 )
 
 func StringToGraphType(s string) GraphType {
@@ -22,6 +28,14 @@ func StringToGraphType(s string) GraphType {
 		return Line
 	case "box":
 		return Box
+	case "heatmap":
+		return Heatmap
+	case "histogram":
+		return Histogram
+	case "bar", "stacked":
+		return StackedBar
+	case "scatter":
+		return Scatter
 	default:
 		return Invalid
 	}
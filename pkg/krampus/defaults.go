@@ -6,4 +6,5 @@ var defaults = map[ConfigKey]string{ //nolint: exhaustive // not all keys have d
 	EulerDirKey:    "problems",
 	AdventTokenKey: "default-placeholder",
 	LanguageKey:    "go",
+	GoHelperKey:    "github.com/asphaltbuffet/advent-of-code/internal/common",
 }
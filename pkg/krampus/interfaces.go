@@ -43,4 +43,7 @@ type DownloadConfiguration interface {
 
 	// GetToken returns the authentication token for downloading exercises.
 	GetToken() string
+
+	// GetGoHelper returns the import path of the helper package for new Go exercises.
+	GetGoHelper() string
 }
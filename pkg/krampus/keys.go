@@ -10,6 +10,35 @@ const (
 	ConfigDirKey ConfigKey = "config-dir" // Configuration key for application configuration files.
 	CacheDirKey  ConfigKey = "cache-dir"  // Configuration key for cached application data.
 	InputFileKey ConfigKey = "input-file" // InputFileKey is the configuration key for the default input file name.
+	RunnersKey   ConfigKey = "runners"    // Configuration key for runners of languages without built-in support.
+	VariantsKey  ConfigKey = "variants"   // Configuration key for variants of runners with different toolchains or flags.
+
+	// Theme configuration keys.
+
+	ThemeKey       ConfigKey = "theme"        // Configuration key for graph and terminal styling.
+	ThemePresetKey ConfigKey = "theme.preset" // Configuration key for the light or dark theme preset.
+
+	// Runner configuration keys.
+
+	GoTidyKey       ConfigKey = "go.tidy"            // Configuration key for running 'go mod tidy' before building Go exercises.
+	GoHelperKey     ConfigKey = "go.helper"          // Configuration key for the helper package imported by new Go exercises.
+	PythonInterpKey ConfigKey = "python.interpreter" // Configuration key for the Python interpreter or virtual environment.
+	PythonLibKey    ConfigKey = "python.lib"         // Configuration key for the directory of Python helper modules.
+	NodeLibKey      ConfigKey = "node.lib"           // Configuration key for the directory of JavaScript helper modules.
+	TranspilerKey   ConfigKey = "node.transpiler"    // Configuration key for the command that transpiles TypeScript exercises.
+	CCompilerKey    ConfigKey = "c.compiler"         // Configuration key for the compiler of C exercises.
+	CFlagsKey       ConfigKey = "c.flags"            // Configuration key for the flags passed to the C compiler.
+	CXXCompilerKey  ConfigKey = "cpp.compiler"       // Configuration key for the compiler of C++ exercises.
+	CXXFlagsKey     ConfigKey = "cpp.flags"          // Configuration key for the flags passed to the C++ compiler.
+
+	// Sandbox configuration keys.
+
+	SandboxKey          ConfigKey = "sandbox.enabled"    // Configuration key for running exercises in a sandbox.
+	SandboxCPUTimeKey   ConfigKey = "sandbox.cpu-time"   // Configuration key for the CPU time limit of sandboxed runners.
+	SandboxMemoryKey    ConfigKey = "sandbox.memory"     // Configuration key for the address space limit of sandboxed runners.
+	SandboxOpenFilesKey ConfigKey = "sandbox.open-files" // Configuration key for the open file limit of sandboxed runners.
+	SandboxNetworkKey   ConfigKey = "sandbox.network"    // Configuration key for allowing network access in the sandbox.
+	SandboxWritableKey  ConfigKey = "sandbox.writable"   // Configuration key for directories sandboxed runners may write to.
 
 	// Advent of Code configuration keys.
 
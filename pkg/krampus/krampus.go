@@ -44,13 +44,7 @@ func NewConfig(options ...func(*Config)) (Config, error) {
 	cfg.viper.SetFs(cfg.fs)
 
 	// set up logger
-	w := os.Stderr
-	cfg.logger = slog.New(
-		tint.NewHandler(w, &tint.Options{
-			Level:      slog.LevelInfo,
-			TimeFormat: time.StampMilli,
-		}),
-	)
+	cfg.logger = newLogger(slog.LevelInfo, time.StampMilli)
 	slog.SetDefault(cfg.logger)
 
 	// set up viper
@@ -60,6 +54,15 @@ func NewConfig(options ...func(*Config)) (Config, error) {
 
 	_ = cfg.viper.BindEnv(string(AdventTokenKey), "ELF_ADVENT_TOKEN")
 	_ = cfg.viper.BindEnv(string(LanguageKey), "ELF_LANGUAGE")
+	_ = cfg.viper.BindEnv(string(ThemePresetKey), "ELF_THEME")
+	_ = cfg.viper.BindEnv(string(GoTidyKey), "ELF_GO_TIDY")
+	_ = cfg.viper.BindEnv(string(PythonInterpKey), "ELF_PYTHON")
+	_ = cfg.viper.BindEnv(string(TranspilerKey), "ELF_TS_TRANSPILER")
+	_ = cfg.viper.BindEnv(string(CCompilerKey), "CC")
+	_ = cfg.viper.BindEnv(string(CFlagsKey), "CFLAGS")
+	_ = cfg.viper.BindEnv(string(CXXCompilerKey), "CXX")
+	_ = cfg.viper.BindEnv(string(CXXFlagsKey), "CXXFLAGS")
+	_ = cfg.viper.BindEnv(string(SandboxKey), "ELF_SANDBOX")
 
 	for k, v := range defaults {
 		cfg.viper.SetDefault(string(k), v)
@@ -102,6 +105,9 @@ func NewConfig(options ...func(*Config)) (Config, error) {
 		cfg.logger.Debug("starting with config file", "config", cfg.viper.ConfigFileUsed())
 	}
 
+	applyTerminalTheme(cfg.GetTheme())
+	cfg.configureRunners()
+
 	return cfg, nil
 }
 
@@ -216,3 +222,11 @@ func (c Config) GetBaseDir() string {
 func (c Config) GetInputFilename() string {
 	return c.viper.GetString(string(InputFileKey))
 }
+
+// GetGoHelper returns the import path of the package providing BaseExercise to new Go
+// exercises.
+//
+// An empty string means new exercises do not import a helper package.
+func (c Config) GetGoHelper() string {
+	return c.viper.GetString(string(GoHelperKey))
+}
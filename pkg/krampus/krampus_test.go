@@ -4,10 +4,13 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/spf13/afero"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	"github.com/asphaltbuffet/elf/pkg/runners"
 )
 
 var (
@@ -190,6 +193,7 @@ func TestDefaultConfig(t *testing.T) {
 		wantToken    = "default-placeholder"
 		wantLanguage = "go"
 		wantBaseDir  = "exercises"
+		wantGoHelper = "github.com/asphaltbuffet/advent-of-code/internal/common"
 	)
 
 	cfgPath, err := os.UserConfigDir()
@@ -214,8 +218,241 @@ func TestDefaultConfig(t *testing.T) {
 		assert.Equal(t, wantConfigDir, got.GetConfigDir(), "default config dir")
 		assert.Equal(t, wantCacheDir, got.GetCacheDir(), "default cache dir")
 		assert.Equal(t, wantBaseDir, got.GetBaseDir(), "default base dir")
+		assert.Equal(t, wantGoHelper, got.GetGoHelper(), "default go helper")
 
 		assert.NotNil(t, got.GetLogger(), "default logger should not be nil")
 		assert.NotNil(t, got.GetFs(), "default fs should not be nil")
 	}
 }
+
+func TestConfig_GetTheme(t *testing.T) {
+	tests := []struct {
+		name   string
+		config string
+		want   Theme
+	}{
+		{
+			name:   "no theme",
+			config: "language = \"go\"\n",
+			want:   Theme{},
+		},
+		{
+			name: "preset and implementation styles",
+			config: `[theme]
+preset = "dark"
+
+[theme.implementations.Python]
+color = "#ffd43b"
+line = "dashed"
+marker = "triangle"
+`,
+			want: Theme{
+				Preset: DarkPreset,
+				Implementations: map[string]ImplementationStyle{
+					"python": {Color: "#ffd43b", Line: "dashed", Marker: "triangle"},
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tfs := afero.NewMemMapFs()
+
+			// config files are searched for in the working directory
+			path, err := filepath.Abs("theme.toml")
+			require.NoError(t, err)
+			require.NoError(t, afero.WriteFile(tfs, path, []byte(tt.config), 0o600))
+
+			got, err := NewConfig(WithFile("theme.toml"), WithFs(tfs))
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.want, got.GetTheme())
+		})
+	}
+}
+
+func TestConfig_GetRunners(t *testing.T) {
+	cwd, err := os.Getwd()
+	require.NoError(t, err)
+
+	tests := []struct {
+		name         string
+		config       string
+		want         map[string]runners.Definition
+		registered   []string
+		unregistered []string
+	}{
+		{
+			name:   "no runners",
+			config: "language = \"go\"\n",
+			want:   nil,
+		},
+		{
+			name: "configured runners",
+			config: `[runners.zig]
+build = "zig build-exe runtime-wrapper.zig"
+run = "./runtime-wrapper"
+wrapper = "/templates/runtime-wrapper.zig.tmpl"
+
+[runners.hs]
+name = "Haskell"
+dir = "haskell"
+run = "runghc Main.hs"
+template = "templates/exercise.hs.tmpl"
+
+[runners.runghc]
+dir = "Haskell"
+run = "runghc Main.hs"
+
+[runners.golang]
+dir = "go"
+run = "go run ."
+
+[runners.go]
+run = "go run ."
+`,
+			want: map[string]runners.Definition{
+				"zig": {
+					Build:   "zig build-exe runtime-wrapper.zig",
+					Run:     "./runtime-wrapper",
+					Wrapper: "/templates/runtime-wrapper.zig.tmpl",
+				},
+				"hs": {
+					Name:     "Haskell",
+					Dir:      "haskell",
+					Run:      "runghc Main.hs",
+					Template: filepath.Join(cwd, "templates", "exercise.hs.tmpl"),
+				},
+				"runghc": {
+					Dir: "Haskell",
+					Run: "runghc Main.hs",
+				},
+				"golang": {
+					Dir: "go",
+					Run: "go run .",
+				},
+				"go": {
+					Run: "go run .",
+				},
+			},
+			registered:   []string{"zig", "hs"},
+			unregistered: []string{"go", "golang", "runghc"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tfs := afero.NewMemMapFs()
+
+			// config files are searched for in the working directory
+			path, err := filepath.Abs("runners.toml")
+			require.NoError(t, err)
+			require.NoError(t, afero.WriteFile(tfs, path, []byte(tt.config), 0o600))
+
+			got, err := NewConfig(WithFile("runners.toml"), WithFs(tfs))
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.want, got.GetRunners())
+
+			for _, lang := range tt.registered {
+				_, ok := runners.Lookup(lang)
+				assert.True(t, ok, "%s should be registered", lang)
+			}
+
+			// built-in runners are never replaced, nor their directories or those of other
+			// runners reused
+			for _, lang := range tt.unregistered {
+				_, ok := runners.Lookup(lang)
+				assert.False(t, ok, "%s should not be registered", lang)
+			}
+		})
+	}
+}
+
+func TestConfig_GetVariants(t *testing.T) {
+	const config = `[variants.go-nobounds]
+runner = "go"
+name = "Go -B"
+build-flags = ["-gcflags=-B"]
+
+[variants.go122]
+runner = "go"
+env = ["GOTOOLCHAIN=go1.22.0"]
+
+[variants.py-flags]
+runner = "py"
+build-flags = ["-O"]
+`
+
+	tfs := afero.NewMemMapFs()
+
+	// config files are searched for in the working directory
+	path, err := filepath.Abs("variants.toml")
+	require.NoError(t, err)
+	require.NoError(t, afero.WriteFile(tfs, path, []byte(config), 0o600))
+
+	got, err := NewConfig(WithFile("variants.toml"), WithFs(tfs))
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]runners.Variant{
+		"go-nobounds": {Runner: "go", Name: "Go -B", BuildFlags: []string{"-gcflags=-B"}},
+		"go122":       {Runner: "go", Env: []string{"GOTOOLCHAIN=go1.22.0"}},
+		"py-flags":    {Runner: "py", BuildFlags: []string{"-O"}},
+	}, got.GetVariants())
+
+	_, ok := runners.LookupVariant("go-nobounds")
+	assert.True(t, ok, "valid variant should be registered")
+
+	_, ok = runners.LookupVariant("py-flags")
+	assert.False(t, ok, "unsupported variant should be skipped")
+}
+
+func TestConfig_GetSandbox(t *testing.T) {
+	tests := []struct {
+		name   string
+		config string
+		want   runners.Sandbox
+	}{
+		{
+			name:   "disabled by default",
+			config: "language = \"go\"\n",
+			want:   runners.Sandbox{},
+		},
+		{
+			name: "limits",
+			config: `[sandbox]
+enabled = true
+cpu-time = "30s"
+memory = "512mb"
+open-files = 64
+writable = ["/tmp/elf-output"]
+`,
+			want: runners.Sandbox{
+				Enabled:   true,
+				CPUTime:   30 * time.Second,
+				Memory:    512 << 20,
+				OpenFiles: 64,
+				Writable:  []string{"/tmp/elf-output"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tfs := afero.NewMemMapFs()
+
+			// config files are searched for in the working directory
+			path, err := filepath.Abs("sandbox.toml")
+			require.NoError(t, err)
+			require.NoError(t, afero.WriteFile(tfs, path, []byte(tt.config), 0o600))
+
+			got, err := NewConfig(WithFile("sandbox.toml"), WithFs(tfs))
+			require.NoError(t, err)
+
+			t.Cleanup(func() { runners.Configure(runners.Settings{}) })
+
+			assert.Equal(t, tt.want, got.GetSandbox())
+		})
+	}
+}
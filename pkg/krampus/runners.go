@@ -0,0 +1,109 @@
+package krampus
+
+import (
+	"path/filepath"
+	"slices"
+
+	"github.com/lmittmann/tint"
+
+	"github.com/asphaltbuffet/elf/pkg/runners"
+)
+
+// GetRunners returns the configured runner definitions, keyed by language. Relative wrapper
+// and template paths are resolved against the directory of the config file.
+//
+// An invalid runners section is logged and ignored.
+func (c Config) GetRunners() map[string]runners.Definition {
+	var defs map[string]runners.Definition
+
+	if err := c.viper.UnmarshalKey(string(RunnersKey), &defs); err != nil {
+		c.logger.Warn("ignoring invalid runner configuration", tint.Err(err))
+		return nil
+	}
+
+	cfgDir := filepath.Dir(c.viper.ConfigFileUsed())
+
+	for lang, def := range defs {
+		def.Wrapper = resolvePath(cfgDir, def.Wrapper)
+		def.Template = resolvePath(cfgDir, def.Template)
+		defs[lang] = def
+	}
+
+	return defs
+}
+
+func resolvePath(dir, path string) string {
+	if path == "" || filepath.IsAbs(path) {
+		return path
+	}
+
+	return filepath.Join(dir, path)
+}
+
+// GetVariants returns the configured runner variants, keyed by variant name.
+//
+// An invalid variants section is logged and ignored.
+func (c Config) GetVariants() map[string]runners.Variant {
+	var variants map[string]runners.Variant
+
+	if err := c.viper.UnmarshalKey(string(VariantsKey), &variants); err != nil {
+		c.logger.Warn("ignoring invalid variant configuration", tint.Err(err))
+		return nil
+	}
+
+	return variants
+}
+
+// GetSandbox returns the sandbox for runner processes. The memory limit may be given with a
+// unit, e.g. "512mb" or "2gb".
+func (c Config) GetSandbox() runners.Sandbox {
+	return runners.Sandbox{
+		Enabled:   c.viper.GetBool(string(SandboxKey)),
+		CPUTime:   c.viper.GetDuration(string(SandboxCPUTimeKey)),
+		Memory:    uint64(c.viper.GetSizeInBytes(string(SandboxMemoryKey))),
+		OpenFiles: c.viper.GetUint64(string(SandboxOpenFilesKey)),
+		Network:   c.viper.GetBool(string(SandboxNetworkKey)),
+		Writable:  c.viper.GetStringSlice(string(SandboxWritableKey)),
+	}
+}
+
+// configureRunners applies the runner settings and makes the configured runners available
+// alongside the built-in runners, followed by their variants. Invalid definitions are logged
+// and skipped. Runners are registered in order of language key, so when two of them claim the
+// same directory it is always the later one that is skipped.
+func (c Config) configureRunners() {
+	runners.Configure(runners.Settings{
+		CacheDir:    c.GetCacheDir(),
+		GoModTidy:   c.viper.GetBool(string(GoTidyKey)),
+		Python:      c.viper.GetString(string(PythonInterpKey)),
+		PythonLib:   c.viper.GetString(string(PythonLibKey)),
+		NodeLib:     c.viper.GetString(string(NodeLibKey)),
+		Transpiler:  c.viper.GetString(string(TranspilerKey)),
+		CCompiler:   c.viper.GetString(string(CCompilerKey)),
+		CFlags:      c.viper.GetStringSlice(string(CFlagsKey)),
+		CXXCompiler: c.viper.GetString(string(CXXCompilerKey)),
+		CXXFlags:    c.viper.GetStringSlice(string(CXXFlagsKey)),
+		Sandbox:     c.GetSandbox(),
+	})
+
+	defs := c.GetRunners()
+
+	langs := make([]string, 0, len(defs))
+	for lang := range defs {
+		langs = append(langs, lang)
+	}
+
+	slices.Sort(langs)
+
+	for _, lang := range langs {
+		if err := runners.Register(lang, defs[lang]); err != nil {
+			c.logger.Warn("skipping runner", "language", lang, tint.Err(err))
+		}
+	}
+
+	for key, v := range c.GetVariants() {
+		if err := runners.RegisterVariant(key, v); err != nil {
+			c.logger.Warn("skipping variant", "variant", key, tint.Err(err))
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package krampus
+
+import (
+	"io"
+	"log/slog"
+	"os"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/lmittmann/tint"
+	"github.com/muesli/termenv"
+)
+
+const (
+	LightPreset string = "light" // LightPreset draws dark plot elements on a white background.
+	DarkPreset  string = "dark"  // DarkPreset draws light plot elements on a black background.
+)
+
+// Theme holds the styling configuration for graphs and terminal output.
+type Theme struct {
+	// Preset selects the light or dark base theme. When empty, plots use the light preset and
+	// terminal colors adapt to the detected background.
+	Preset string `mapstructure:"preset"`
+
+	// Implementations maps an implementation name to its plot style. Names are matched
+	// without regard to case.
+	Implementations map[string]ImplementationStyle `mapstructure:"implementations"`
+}
+
+// ImplementationStyle is the plot style for a single implementation. Empty fields use the
+// default for the implementation.
+type ImplementationStyle struct {
+	Color  string `mapstructure:"color"`  // hex color, e.g. "#00add8"
+	Line   string `mapstructure:"line"`   // solid, dashed, or dotted
+	Marker string `mapstructure:"marker"` // circle, ring, square, box, triangle, cross, plus, or none
+}
+
+// GetTheme returns the configured theme.
+//
+// An invalid theme section is logged and ignored.
+func (c Config) GetTheme() Theme {
+	var theme Theme
+
+	if err := c.viper.UnmarshalKey(string(ThemeKey), &theme); err != nil {
+		c.logger.Warn("ignoring invalid theme configuration", tint.Err(err))
+		return Theme{}
+	}
+
+	// the preset may also be set from the environment
+	theme.Preset = c.viper.GetString(string(ThemePresetKey))
+
+	return theme
+}
+
+// colorEnabled reports whether log output to w should be colored. Colors are disabled for
+// anything but a terminal and when NO_COLOR is set, the same as lipgloss does for styled
+// output. NO_COLOR doesn't affect graph colors.
+func colorEnabled(w io.Writer) bool {
+	return termenv.NewOutput(w).EnvColorProfile() != termenv.Ascii
+}
+
+// newLogger returns the application logger, writing to stderr.
+func newLogger(level slog.Level, timeFormat string) *slog.Logger {
+	w := os.Stderr
+
+	return slog.New(
+		tint.NewHandler(w, &tint.Options{
+			Level:      level,
+			TimeFormat: timeFormat,
+			NoColor:    !colorEnabled(w),
+		}),
+	)
+}
+
+// applyTerminalTheme sets the terminal background used by adaptive colors when a preset is
+// configured. Without one, lipgloss detects the background itself.
+func applyTerminalTheme(theme Theme) {
+	switch theme.Preset {
+	case DarkPreset:
+		lipgloss.SetHasDarkBackground(true)
+	case LightPreset:
+		lipgloss.SetHasDarkBackground(false)
+	}
+}
@@ -1,11 +1,14 @@
 package runners
 
 import (
-	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
+	"log/slog"
 	"os/exec"
 	"strings"
 	"sync"
@@ -25,9 +28,48 @@ type Task struct {
 	// Input is the input data for the task.
 	Input string `json:"input"`
 
+	// InputID identifies the input so it is only sent to a runner process once. A task with an
+	// input ID registers its input under that ID, replacing the input registered before. A
+	// later task with the same ID and no input uses the registered input.
+	// This field is optional.
+	InputID string `json:"input_id,omitempty"`
+
 	// OutputDir is the directory where the task should store its output.
 	// This field is optional.
 	OutputDir string `json:"output_dir,omitempty"`
+
+	// OnProgress is called with the progress reported by the task while it runs.
+	// This field is optional.
+	OnProgress func(Progress) `json:"-"`
+}
+
+// Progress is reported by a running task, either as a count of completed steps or as a
+// fraction. Runner processes send it as a line like
+//
+//	{"task_id": "solve.1", "progress": {"done": 12, "total": 100}}
+//
+// while the task runs. The task ID may be left out.
+type Progress struct {
+	// TaskID is the unique identifier for the task reporting progress.
+	TaskID string `json:"-"`
+	// Done is the number of completed steps.
+	Done int64 `json:"done,omitempty"`
+	// Total is the number of steps, or zero if unknown.
+	Total int64 `json:"total,omitempty"`
+	// Fraction is the completed fraction of the task, for tasks that do not count steps.
+	Fraction float64 `json:"fraction,omitempty"`
+}
+
+// Ratio returns the completed fraction of the task, if it is known.
+func (p Progress) Ratio() (float64, bool) {
+	switch {
+	case p.Total > 0:
+		return min(1, float64(p.Done)/float64(p.Total)), true
+	case p.Fraction > 0:
+		return min(1, p.Fraction), true
+	default:
+		return 0, false
+	}
 }
 
 // A Result represents the outcome of a Task.
@@ -38,101 +80,295 @@ type Result struct {
 	Ok bool `json:"ok"`
 	// Output is the output of the task, if successful.
 	Output string `json:"output"`
-	// Duration is the amount of time it took for the task to complete.
+	// Trace is the stack trace of a task that failed with a panic or exception, if the runner
+	// provides one. Frames of the runner wrapper are left out.
+	Trace string `json:"trace,omitempty"`
+	// Duration is the amount of time it took for the task to complete, excluding parsing.
 	Duration float64 `json:"duration"`
+	// ParseDuration is the amount of time it took to parse the input, for exercises with a
+	// parse step. It is zero for tasks that reuse an input parsed for an earlier task.
+	ParseDuration float64 `json:"parse_duration,omitempty"`
+	// Limit names the sandbox limit that stopped the task, if one did. Only the CPU time
+	// limit is detected; memory and open file limits make the task fail like any other error.
+	Limit string `json:"limit,omitempty"`
+	// Overhead is the time spent sending the task and reading its result, in seconds. It is
+	// measured by elf and not included in Duration.
+	Overhead float64 `json:"-"`
 }
 
-type customWriter struct {
-	pending []byte
-	entries [][]byte
-	mux     sync.Mutex
+// InputID returns an ID for an input based on its content.
+func InputID(input string) string {
+	sum := sha256.Sum256([]byte(input))
+
+	return hex.EncodeToString(sum[:8])
 }
 
-// Write writes the given bytes to the custom writer.
-//
-// Newline characters ('\n') are used to flush the pending buffer and append
-// the current contents to the list of entries.
-func (c *customWriter) Write(b []byte) (int, error) {
-	var n int
-
-	c.mux.Lock()
-	for _, x := range b {
-		if x == '\n' {
-			c.entries = append(c.entries, c.pending)
-			c.pending = nil
-		} else {
-			c.pending = append(c.pending, x)
-		}
-		n++
+const (
+	// maxMessageSize is the longest line a runner process may write to stdout.
+	maxMessageSize = 64 << 20
+
+	// maxStderrSize is how much of the end of a runner process's stderr is kept.
+	maxStderrSize = 64 << 10
+
+	// pendingMessages is how many messages are read ahead of the task being run.
+	pendingMessages = 16
+)
+
+// A message is a line written to stdout by a runner process.
+type message struct {
+	// result is the decoded result, or nil if the line is progress or debug output.
+	result *Result
+	// progress is the decoded progress, or nil if the line is a result or debug output.
+	progress *Progress
+	line     []byte
+	err      error
+}
+
+// decodeMessage decodes a line written by a runner process. Anything that is not a result or
+// progress is considered debug output.
+func decodeMessage(line []byte) message {
+	var m struct {
+		Result
+		Progress *Progress `json:"progress"`
+	}
+
+	if err := json.Unmarshal(line, &m); err != nil {
+		return message{line: line}
 	}
-	c.mux.Unlock()
 
-	return n, nil
+	if m.Progress != nil {
+		m.Progress.TaskID = m.TaskID
+		return message{progress: m.Progress, line: line}
+	}
+
+	return message{result: &m.Result, line: line}
 }
 
-// GetEntry returns the next entry from the custom writer, or nil if there are no more entries.
-//
-// If there are no more entries, the function returns an error.
-func (c *customWriter) GetEntry() ([]byte, error) {
-	c.mux.Lock()
-	defer c.mux.Unlock()
+// tailBuffer keeps the end of what is written to it. Runner processes write their last error
+// to stderr just before exiting, so the end is what explains a crash.
+type tailBuffer struct {
+	mux sync.Mutex
+	buf []byte
+	max int
+}
+
+func (t *tailBuffer) Write(b []byte) (int, error) {
+	t.mux.Lock()
+	defer t.mux.Unlock()
+
+	t.buf = append(t.buf, b...)
 
-	if len(c.entries) == 0 {
-		return nil, errors.New("no entries")
+	// trimming only once the buffer doubles keeps writes cheap
+	if len(t.buf) > 2*t.max {
+		t.buf = append(t.buf[:0], t.buf[len(t.buf)-t.max:]...)
 	}
 
-	var x []byte
-	x, c.entries = c.entries[0], c.entries[1:]
+	return len(b), nil
+}
+
+// String returns the end of the written output.
+func (t *tailBuffer) String() string {
+	t.mux.Lock()
+	defer t.mux.Unlock()
 
-	return x, nil
+	if len(t.buf) > t.max {
+		return string(t.buf[len(t.buf)-t.max:])
+	}
+
+	return string(t.buf)
 }
 
+// setupBuffers connects the output of a runner process to be read by startProcess and returns
+// the pipe that tasks are written to.
 func setupBuffers(cmd *exec.Cmd) (io.WriteCloser, error) {
-	stdoutWriter := &customWriter{}
-	cmd.Stdout = stdoutWriter
-	cmd.Stderr = new(bytes.Buffer)
+	cmd.Stderr = &tailBuffer{max: maxStderrSize}
+
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
 
-	return cmd.StdinPipe()
+	trackProcess(cmd, stdout)
+
+	return stdin, nil
 }
 
-func checkWait(cmd *exec.Cmd) ([]byte, error) {
-	const checkWaitDelay time.Duration = 10 * time.Millisecond
+// readMessage waits for the next message from a runner process. It returns an error if the
+// process exits first.
+func readMessage(cmd *exec.Cmd) (message, error) {
+	p, ok := trackedProcess(cmd)
+	if !ok {
+		return message{}, errors.New("runner process not started")
+	}
 
-	c := cmd.Stdout.(*customWriter) //nolint:errcheck // we will handle errors in the loop
+	msg, ok := <-p.messages
+	if !ok {
+		<-p.done
+		return message{}, exitError(cmd)
+	}
 
-	for {
-		e, err := c.GetEntry()
+	return msg, msg.err
+}
+
+// exitError describes why a runner process exited before returning a result.
+func exitError(cmd *exec.Cmd) error {
+	stderr := cmd.Stderr.(*tailBuffer).String() //nolint:errcheck // set up by setupBuffers
+
+	crashErr := &CrashError{ExitCode: cmd.ProcessState.ExitCode(), Stderr: stderr}
+
+	if settings.Sandbox.Enabled {
+		if limit := limitExceeded(cmd.ProcessState, settings.Sandbox); limit != "" {
+			return &LimitError{Limit: limit, Sandbox: settings.Sandbox}
+		}
+
+		crashErr.Limits = settings.Sandbox.failureLimits()
+	}
+
+	return crashErr
+}
+
+// maxRestarts is how many times a runner process is restarted after dying before the run is
+// abandoned.
+const maxRestarts = 3
+
+// ErrTooManyRestarts is returned when a runner process keeps dying.
+var ErrTooManyRestarts = errors.New("runner process restarted too many times")
+
+// CrashError reports a runner process that exited while running a task.
+type CrashError struct {
+	ExitCode int
+	Stderr   string
+	// Limits lists the sandbox limits in effect that may have caused the crash.
+	Limits string
+}
+
+func (e *CrashError) Error() string {
+	if e.Limits != "" {
+		return fmt.Sprintf("run failed with exit code %d (sandbox %s): %s", e.ExitCode, e.Limits, e.Stderr)
+	}
+
+	return fmt.Sprintf("run failed with exit code %d: %s", e.ExitCode, e.Stderr)
+}
+
+// runTaskRestarting runs a task like runTask. If the runner process dies, the task fails with
+// the output of the process and the process is started again so the remaining tasks can run.
+// The process is restarted at most maxRestarts times.
+func runTaskRestarting(task *Task, cmd **exec.Cmd, stdin *io.WriteCloser, restarts *int) (*Result, error) {
+	r, err := runTask(task, *stdin, *cmd)
+
+	var crashErr *CrashError
+
+	switch {
+	case err == nil && !exited(*cmd):
+		return r, nil
+	case err != nil && !errors.As(err, &crashErr):
+		return nil, err
+	case *restarts >= maxRestarts:
 		if err == nil {
-			return e, nil
+			err = exitError(*cmd)
 		}
 
-		if cmd.ProcessState != nil {
-			return nil, fmt.Errorf(
-				"run failed with exit code %d: %s",
-				cmd.ProcessState.ExitCode(),
-				cmd.Stderr.(*bytes.Buffer).String())
+		return nil, fmt.Errorf("%w: %w", ErrTooManyRestarts, err)
+	}
+
+	*restarts++
+
+	slog.LogAttrs(context.TODO(), slog.LevelWarn, "restarting runner process",
+		slog.String("task", task.TaskID),
+		slog.Int("restart", *restarts),
+	)
+
+	_ = (*stdin).Close()
+
+	next, nextStdin, restartErr := restartProcess(*cmd)
+	if restartErr != nil {
+		return nil, fmt.Errorf("restarting runner process: %w", restartErr)
+	}
+
+	*cmd, *stdin = next, nextStdin
+
+	if err == nil {
+		// the task finished, e.g. with a sandbox limit, but took the process with it
+		return r, nil
+	}
+
+	return &Result{TaskID: task.TaskID, Ok: false, Output: strings.TrimSpace(crashErr.Error())}, nil
+}
+
+// runTask sends a task to a runner process and reads its result. A task stopped by a sandbox
+// limit gives a failed result naming the limit.
+func runTask(task *Task, stdin io.Writer, cmd *exec.Cmd) (*Result, error) {
+	p, ok := trackedProcess(cmd)
+	if !ok {
+		return nil, errors.New("runner process not started")
+	}
+
+	sent := *task
+	if task.InputID != "" && task.InputID == p.inputID {
+		sent.Input = "" // registered with an earlier task
+	}
+
+	start := time.Now()
+
+	taskJSON, err := json.Marshal(&sent)
+	if err != nil {
+		return nil, fmt.Errorf("marshalling task to json: %w", err)
+	}
+
+	_, err = stdin.Write(append(taskJSON, '\n'))
+	if err != nil {
+		return nil, fmt.Errorf("writing task to stdin: %w", err)
+	}
+
+	if task.InputID != "" {
+		p.inputID = task.InputID
+	}
+
+	r, err := readResult(cmd, task)
+	if err != nil {
+		var limitErr *LimitError
+		if errors.As(err, &limitErr) {
+			return &Result{TaskID: task.TaskID, Ok: false, Output: limitErr.Error(), Limit: limitErr.Limit}, nil
 		}
 
-		time.Sleep(checkWaitDelay)
+		return nil, err
 	}
+
+	r.Overhead = max(0, time.Since(start).Seconds()-r.Duration-r.ParseDuration)
+
+	return r, nil
 }
 
-func readJSONFromCommand(res interface{}, cmd *exec.Cmd) error {
+// readResult reads messages from a runner process until it sends a result. Progress of the
+// task is passed to its OnProgress; progress of other tasks is ignored.
+func readResult(cmd *exec.Cmd, task *Task) (*Result, error) {
 	for {
-		inp, err := checkWait(cmd)
+		msg, err := readMessage(cmd)
 		if err != nil {
-			return err
+			return nil, err
 		}
 
-		err = json.Unmarshal(inp, res)
-		if err != nil {
-			// anything returned as an error is considered a debug message
-			style := lipgloss.NewStyle().Foreground(lipgloss.Color("205"))
-			fmt.Printf("[%s] %v\n", style.Render("DBG"), strings.TrimSpace(string(inp)))
-		} else {
-			break
+		switch {
+		case msg.result != nil:
+			return msg.result, nil
+		case msg.progress != nil:
+			if task.OnProgress != nil && (msg.progress.TaskID == "" || msg.progress.TaskID == task.TaskID) {
+				msg.progress.TaskID = task.TaskID
+				task.OnProgress(*msg.progress)
+			}
+		default:
+			printDebug(msg.line)
 		}
 	}
+}
 
-	return nil
+// printDebug shows output from exercise code that is not part of a result.
+func printDebug(b []byte) {
+	style := lipgloss.NewStyle().Foreground(lipgloss.Color("205"))
+	fmt.Printf("[%s] %v\n", style.Render("DBG"), strings.TrimSpace(string(b)))
 }
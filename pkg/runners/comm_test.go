@@ -1,94 +1,157 @@
 package runners
 
 import (
-	"bytes"
 	"fmt"
 	"os/exec"
+	"runtime"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
-func Test_customWriter_Write(t *testing.T) {
-	type args struct {
-		b []byte
+func Test_tailBuffer(t *testing.T) {
+	tests := []struct {
+		name   string
+		writes []string
+		want   string
+	}{
+		{"empty", nil, ""},
+		{"within limit", []string{"abc", "def"}, "abcdef"},
+		{"keeps the end", []string{"panic: ", "oops\n", "trace"}, "oops\ntrace"},
+		{"single large write", []string{"0123456789abcdef"}, "6789abcdef"},
 	}
 
-	type output struct {
-		n       int
-		entries [][]byte
-		pending []byte
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := &tailBuffer{max: 10}
+
+			for _, w := range tt.writes {
+				n, err := b.Write([]byte(w))
+				require.NoError(t, err)
+				assert.Equal(t, len(w), n)
+			}
+
+			assert.Equal(t, tt.want, b.String())
+		})
 	}
+}
 
+func Test_decodeMessage(t *testing.T) {
 	tests := []struct {
-		name      string
-		c         *customWriter
-		args      args
-		want      output
-		assertion assert.ErrorAssertionFunc
+		name         string
+		line         string
+		want         *Result
+		wantProgress *Progress
 	}{
-		{
-			name: "single line",
-			c:    &customWriter{},
-			args: args{
-				b: []byte("fake\n"),
-			},
-			want: output{
-				n:       5,
-				entries: [][]byte{[]byte("fake")},
-				pending: nil,
-			},
-			assertion: assert.NoError,
-		},
+		{"result", `{"task_id":"solve.1","ok":true,"output":"42","duration":0.5}`, &Result{TaskID: "solve.1", Ok: true, Output: "42", Duration: 0.5}, nil},
+		{"debug output", "parsed 12 lines", nil, nil},
+		{"json that is not a result", `[1, 2, 3]`, nil, nil},
+		{"progress", `{"task_id":"solve.1","progress":{"done":3,"total":10}}`, nil, &Progress{TaskID: "solve.1", Done: 3, Total: 10}},
+		{"progress without task", `{"progress":{"fraction":0.25}}`, nil, &Progress{Fraction: 0.25}},
 	}
+
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := tt.c.Write(tt.args.b)
-			cw := tt.c
+			got := decodeMessage([]byte(tt.line))
 
-			tt.assertion(t, err)
-			if err == nil {
-				assert.Equal(t, tt.want.entries, cw.entries)
-				assert.Equal(t, tt.want.pending, cw.pending)
-				assert.Equal(t, tt.want.n, got)
-			}
+			assert.Equal(t, tt.want, got.result)
+			assert.Equal(t, tt.wantProgress, got.progress)
+			assert.Equal(t, tt.line, string(got.line))
+			assert.NoError(t, got.err)
 		})
 	}
 }
 
-func Test_customWriter_GetEntry(t *testing.T) {
+func Test_readResult(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("requires a POSIX shell")
+	}
+
+	// a result larger than the default scanner buffer, preceded by debug output
+	const size = 1 << 20
+
+	script := fmt.Sprintf(`read -r task; echo debug; printf '{"task_id":"solve.1","ok":true,"output":"'
+head -c %d /dev/zero | tr '\000' x; printf '"}\n'`, size)
+
+	cmd := exec.Command("sh", "-c", script) //nolint:gosec // test script
+
+	stdin, err := setupBuffers(cmd)
+	require.NoError(t, err)
+	require.NoError(t, startProcess(cmd))
+
+	t.Cleanup(func() { _ = stopProcess(cmd, "sh") })
+
+	got, err := runTask(&Task{TaskID: "solve.1", Part: PartOne}, stdin, cmd)
+	require.NoError(t, err)
+	assert.True(t, got.Ok)
+	assert.Equal(t, strings.Repeat("x", size), got.Output)
+
+	_, err = readResult(cmd, &Task{TaskID: "solve.2", Part: PartTwo})
+
+	var crashErr *CrashError
+	require.ErrorAs(t, err, &crashErr, "exiting without a result should be reported")
+	assert.Equal(t, 0, crashErr.ExitCode)
+}
+
+func Test_readResult_progress(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("requires a POSIX shell")
+	}
+
+	script := `read -r task
+echo '{"task_id":"solve.1","progress":{"done":1,"total":4}}'
+echo '{"task_id":"solve.2","progress":{"done":2,"total":4}}'
+echo '{"progress":{"fraction":0.75}}'
+echo '{"task_id":"solve.1","ok":true,"output":"42"}'`
+
+	cmd := exec.Command("sh", "-c", script)
+
+	stdin, err := setupBuffers(cmd)
+	require.NoError(t, err)
+	require.NoError(t, startProcess(cmd))
+
+	t.Cleanup(func() { _ = stopProcess(cmd, "sh") })
+
+	var got []Progress
+
+	task := &Task{
+		TaskID:     "solve.1",
+		Part:       PartOne,
+		OnProgress: func(p Progress) { got = append(got, p) },
+	}
+
+	r, err := runTask(task, stdin, cmd)
+	require.NoError(t, err)
+	assert.Equal(t, "42", r.Output)
+
+	// progress of other tasks is ignored
+	assert.Equal(t, []Progress{
+		{TaskID: "solve.1", Done: 1, Total: 4},
+		{TaskID: "solve.1", Fraction: 0.75},
+	}, got)
+}
+
+func TestProgress_Ratio(t *testing.T) {
 	tests := []struct {
-		name      string
-		entries   [][]byte
-		want      []byte
-		assertion assert.ErrorAssertionFunc
-		errText   string
+		name   string
+		p      Progress
+		want   float64
+		wantOk bool
 	}{
-		{"no entries to get", [][]byte{}, nil, assert.Error, "no entries"},
-		{"single entry", [][]byte{[]byte("test data")}, []byte("test data"), assert.NoError, ""},
-		{
-			"multiple entries",
-			[][]byte{
-				[]byte("test data 1"),
-				[]byte("test data 2"),
-				[]byte("fake data 3"),
-			},
-			[]byte("test data 1"), assert.NoError, "",
-		},
+		{"counted", Progress{Done: 1, Total: 4}, 0.25, true},
+		{"past the total", Progress{Done: 5, Total: 4}, 1, true},
+		{"fraction", Progress{Fraction: 0.5}, 0.5, true},
+		{"unknown total", Progress{Done: 7}, 0, false},
 	}
+
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			c := &customWriter{entries: tt.entries}
+			got, ok := tt.p.Ratio()
 
-			got, err := c.GetEntry()
-
-			tt.assertion(t, err)
-			if err != nil {
-				assert.ErrorContains(t, err, tt.errText)
-			} else {
-				assert.Equal(t, tt.want, got, fmt.Sprintf("expected %q, got %q", tt.want, got))
-				assert.NotContains(t, c.entries, got)
-			}
+			assert.InDelta(t, tt.want, got, 1e-9)
+			assert.Equal(t, tt.wantOk, ok)
 		})
 	}
 }
@@ -113,10 +176,120 @@ func TestSetupBuffers(t *testing.T) {
 			tt.assertion(t, err)
 
 			if err == nil {
-				assert.IsType(t, &customWriter{}, c.Stdout)
-				assert.IsType(t, &bytes.Buffer{}, c.Stderr)
+				t.Cleanup(func() { releaseProcess(c) })
+
+				assert.IsType(t, &tailBuffer{}, c.Stderr)
 				assert.NotNil(t, got)
+
+				_, ok := trackedProcess(c)
+				assert.True(t, ok, "output should be read once started")
 			}
 		})
 	}
 }
+
+// crashingRunner reads tasks and dies on any task with "crash" in its input.
+const crashingRunner = `while read -r task; do
+  case "$task" in
+    *crash*) echo "panic: $task" >&2; exit 2 ;;
+    *) echo '{"task_id":"ok","ok":true,"output":"done"}' ;;
+  esac
+done`
+
+func Test_runTaskRestarting(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("requires a POSIX shell")
+	}
+
+	cmd := exec.Command("sh", "-c", crashingRunner)
+
+	stdin, err := setupBuffers(cmd)
+	require.NoError(t, err)
+	require.NoError(t, startProcess(cmd))
+
+	var restarts int
+
+	t.Cleanup(func() { _ = stopProcess(cmd, "sh") })
+
+	ok := &Task{TaskID: "ok", Part: PartOne, Input: "fine"}
+	crash := &Task{TaskID: "crash", Part: PartTwo, Input: "crash"}
+
+	got, err := runTaskRestarting(ok, &cmd, &stdin, &restarts)
+	require.NoError(t, err)
+	assert.True(t, got.Ok)
+
+	for i := 1; i <= maxRestarts; i++ {
+		first := cmd
+
+		got, err = runTaskRestarting(crash, &cmd, &stdin, &restarts)
+		require.NoError(t, err)
+
+		assert.Equal(t, &Result{TaskID: "crash", Ok: false, Output: got.Output}, got)
+		assert.Contains(t, got.Output, "exit code 2")
+		assert.Contains(t, got.Output, "panic:", "output should include stderr")
+		assert.Equal(t, i, restarts)
+		assert.NotSame(t, first, cmd, "process should be restarted")
+
+		got, err = runTaskRestarting(ok, &cmd, &stdin, &restarts)
+		require.NoError(t, err, "tasks after a crash should run")
+		assert.True(t, got.Ok)
+	}
+
+	_, err = runTaskRestarting(crash, &cmd, &stdin, &restarts)
+	require.ErrorIs(t, err, ErrTooManyRestarts)
+
+	var crashErr *CrashError
+	require.ErrorAs(t, err, &crashErr)
+	assert.Equal(t, 2, crashErr.ExitCode)
+}
+
+func Test_runTask_inputID(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("requires a POSIX shell")
+	}
+
+	// the runner reports whether it was sent the input
+	script := `while read -r task; do
+  case "$task" in
+    *'"input":""'*) sent=false ;;
+    *) sent=true ;;
+  esac
+  echo "{\"task_id\":\"t\",\"ok\":true,\"output\":\"$sent\"}"
+done`
+
+	cmd := exec.Command("sh", "-c", script)
+
+	stdin, err := setupBuffers(cmd)
+	require.NoError(t, err)
+	require.NoError(t, startProcess(cmd))
+
+	t.Cleanup(func() { _ = stopProcess(cmd, "sh") })
+
+	first, second := InputID("first input"), InputID("second input")
+	require.NotEqual(t, first, second)
+	require.Equal(t, first, InputID("first input"), "IDs should depend only on content")
+
+	tests := []struct {
+		name string
+		task Task
+		sent string
+	}{
+		{"registers input", Task{Input: "first input", InputID: first}, "true"},
+		{"reuses registered input", Task{Input: "first input", InputID: first}, "false"},
+		{"without id", Task{Input: "first input"}, "true"},
+		{"keeps registration", Task{Input: "first input", InputID: first}, "false"},
+		{"replaces registration", Task{Input: "second input", InputID: second}, "true"},
+		{"earlier input", Task{Input: "first input", InputID: first}, "true"},
+	}
+
+	// the cases share the runner process and run in order
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := runTask(&tt.task, stdin, cmd)
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.sent, got.Output)
+			assert.GreaterOrEqual(t, got.Overhead, 0.0)
+		})
+	}
+}
@@ -0,0 +1,296 @@
+package runners
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+var (
+	ErrBuiltinRunner     = errors.New("cannot replace built-in runner")
+	ErrInvalidDefinition = errors.New("invalid runner definition")
+	ErrDuplicateDir      = errors.New("implementation directory already in use")
+)
+
+// Definition describes a runner for a language without built-in support. Commands are split
+// on whitespace and run in the exercise directory without a shell.
+//
+// Tasks may carry an input ID, in which case the runner process must keep the input of the
+// last task that had one and use it for later tasks with the same ID and no input.
+type Definition struct {
+	// Name is shown in output and graphs. Defaults to the language key.
+	Name string `mapstructure:"name"`
+
+	// Dir is the implementation directory in each exercise. Defaults to the language key.
+	Dir string `mapstructure:"dir"`
+
+	// Build is run once before the exercise starts. Optional.
+	//
+	// Build and Run may refer to $WORKSPACE, a temporary directory for build output that is
+	// removed after the run, and $WRAPPER, the path of the rendered wrapper.
+	Build string `mapstructure:"build"`
+
+	// Run starts the process that reads tasks from stdin and writes results to stdout.
+	Run string `mapstructure:"run"`
+
+	// Wrapper is the path to a text/template file rendered into the workspace before
+	// building. The written file drops a trailing ".tmpl" from the template name. Optional.
+	Wrapper string `mapstructure:"wrapper"`
+
+	// Template is the path to a text/template file used to scaffold new exercises. The
+	// written file drops a trailing ".tmpl" from the template name. Optional.
+	Template string `mapstructure:"template"`
+}
+
+// builtins are the languages supported without configuration.
+var builtins = maps.Clone(Available)
+
+// definitions holds the configured runners by language key.
+var definitions = map[string]Definition{}
+
+// dirs maps the implementation directory of each configured runner to its language key.
+var dirs = map[string]string{}
+
+// Register adds a configured runner for a language, replacing any earlier definition.
+// Built-in runners cannot be replaced, and each runner needs an implementation directory of
+// its own that isn't the directory of a built-in language.
+func Register(lang string, def Definition) error {
+	lang = strings.ToLower(lang)
+
+	if _, ok := builtins[lang]; ok {
+		return fmt.Errorf("%w: %s", ErrBuiltinRunner, lang)
+	}
+
+	if len(strings.Fields(def.Run)) == 0 {
+		return fmt.Errorf("%w: %s: no run command", ErrInvalidDefinition, lang)
+	}
+
+	if def.Name == "" {
+		def.Name = lang
+	}
+
+	if def.Dir == "" {
+		def.Dir = lang
+	}
+
+	dir := strings.ToLower(def.Dir)
+
+	if _, ok := builtins[dir]; ok {
+		return fmt.Errorf("%w: %s: %s is used by a built-in runner", ErrDuplicateDir, lang, def.Dir)
+	}
+
+	if other, ok := dirs[dir]; ok && other != lang {
+		return fmt.Errorf("%w: %s: %s is used by %s", ErrDuplicateDir, lang, def.Dir, other)
+	}
+
+	if prev, ok := definitions[lang]; ok {
+		delete(dirs, strings.ToLower(prev.Dir))
+	}
+
+	dirs[dir] = lang
+	definitions[lang] = def
+	Available[lang] = func(dir string) Runner {
+		return newGenericRunner(def, dir)
+	}
+
+	return nil
+}
+
+// Lookup returns the configured runner definition for a language.
+func Lookup(lang string) (Definition, bool) {
+	def, ok := definitions[strings.ToLower(lang)]
+	return def, ok
+}
+
+// LanguageForDir returns the language whose implementations are kept in the named exercise
+// subdirectory.
+func LanguageForDir(dir string) (string, bool) {
+	dir = strings.ToLower(dir)
+
+	if lang, ok := dirs[dir]; ok {
+		return lang, true
+	}
+
+	if _, ok := builtins[dir]; ok {
+		return dir, true
+	}
+
+	return "", false
+}
+
+// OutputFilename returns the name a wrapper or scaffold template is written to.
+func OutputFilename(tmpl string) string {
+	return strings.TrimSuffix(filepath.Base(tmpl), ".tmpl")
+}
+
+type genericRunner struct {
+	def             Definition
+	cmd             *exec.Cmd
+	dir             string
+	stdin           io.WriteCloser
+	restarts        int
+	workspace       string
+	wrapperFilepath string
+
+	// env is set by variants
+	env []string
+
+	// writable lists extra directories its processes may write to when sandboxed
+	writable []string
+}
+
+func newGenericRunner(def Definition, dir string) Runner {
+	return &genericRunner{
+		def: def,
+		dir: dir,
+	}
+}
+
+func (g *genericRunner) applyVariant(v Variant) error {
+	if err := v.unsupported(g.def.Name, false, false); err != nil {
+		return err
+	}
+
+	g.env = v.Env
+
+	return nil
+}
+
+func (g *genericRunner) allowWrite(dir string) {
+	g.writable = append(g.writable, dir)
+}
+
+// Start writes the wrapper, runs the build command, and starts the run command.
+func (g *genericRunner) Start() (err error) {
+	workspace, err := newWorkspace()
+	if err != nil {
+		return fmt.Errorf("creating workspace: %w", err)
+	}
+
+	g.workspace = workspace
+
+	// runners that fail to start are not cleaned up
+	defer func() {
+		if err != nil {
+			_ = removeWorkspace(g.workspace)
+			g.workspace = ""
+		}
+	}()
+
+	if g.def.Wrapper != "" {
+		g.wrapperFilepath = filepath.Join(g.workspace, OutputFilename(g.def.Wrapper))
+
+		if err = g.writeWrapper(); err != nil {
+			return err
+		}
+	}
+
+	if build := g.command(g.def.Build); len(build) != 0 {
+		stderrBuffer := new(bytes.Buffer)
+
+		//nolint:gosec // command is provided by the user
+		cmd := exec.Command(build[0], build[1:]...)
+		cmd.Dir = g.dir
+		cmd.Env = withEnv(g.env)
+		cmd.Stdout = stderrBuffer
+		cmd.Stderr = stderrBuffer
+
+		if err = cmd.Run(); err != nil {
+			return fmt.Errorf("build failed: %w: %s", err, stderrBuffer.String())
+		}
+	}
+
+	run := g.command(g.def.Run)
+
+	//nolint:gosec // command is provided by the user
+	g.cmd = exec.Command(run[0], run[1:]...)
+	g.cmd.Dir = g.dir
+	g.cmd.Env = withEnv(g.env)
+
+	stdin, err := setupBuffers(g.cmd)
+	if err != nil {
+		return err
+	}
+
+	g.stdin = stdin
+
+	return startProcess(g.cmd, g.writable...)
+}
+
+// command splits a configured command into arguments and expands variables in each.
+func (g *genericRunner) command(s string) []string {
+	args := strings.Fields(s)
+
+	for i, arg := range args {
+		args[i] = os.Expand(arg, func(key string) string {
+			switch key {
+			case "WORKSPACE":
+				return g.workspace
+			case "WRAPPER":
+				return g.wrapperFilepath
+			default:
+				// variant settings take precedence over the environment
+				for i := len(g.env) - 1; i >= 0; i-- {
+					if k, v, _ := strings.Cut(g.env[i], "="); k == key {
+						return v
+					}
+				}
+
+				return os.Getenv(key)
+			}
+		})
+	}
+
+	return args
+}
+
+func (g *genericRunner) writeWrapper() error {
+	data, err := os.ReadFile(g.def.Wrapper)
+	if err != nil {
+		return fmt.Errorf("reading wrapper template: %w", err)
+	}
+
+	tpl, err := template.New(filepath.Base(g.def.Wrapper)).Parse(string(data))
+	if err != nil {
+		return fmt.Errorf("parsing wrapper template: %w", err)
+	}
+
+	absDir, err := filepath.Abs(g.dir)
+	if err != nil {
+		return err
+	}
+
+	b := new(bytes.Buffer)
+
+	// Dir is the implementation directory, Path is the absolute exercise directory
+	err = tpl.Execute(b, struct{ Dir, Path string }{g.def.Dir, absDir})
+	if err != nil {
+		return fmt.Errorf("executing wrapper template: %w", err)
+	}
+
+	return os.WriteFile(g.wrapperFilepath, b.Bytes(), 0o600)
+}
+
+func (g *genericRunner) Stop() error {
+	return stopProcess(g.cmd, g.def.Name)
+}
+
+func (g *genericRunner) Cleanup() error {
+	return removeWorkspace(g.workspace)
+}
+
+func (g *genericRunner) Run(task *Task) (*Result, error) {
+	return runTaskRestarting(task, &g.cmd, &g.stdin, &g.restarts)
+}
+
+// String returns the configured name of the runner.
+func (g *genericRunner) String() string {
+	return g.def.Name
+}
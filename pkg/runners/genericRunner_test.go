@@ -0,0 +1,213 @@
+package runners
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegister(t *testing.T) {
+	tests := []struct {
+		name      string
+		lang      string
+		def       Definition
+		want      Definition
+		assertion require.ErrorAssertionFunc
+		err       error
+	}{
+		{
+			name:      "defaults",
+			lang:      "Zig",
+			def:       Definition{Run: "./runtime-wrapper"},
+			want:      Definition{Name: "zig", Dir: "zig", Run: "./runtime-wrapper"},
+			assertion: require.NoError,
+		},
+		{
+			name:      "named",
+			lang:      "hs",
+			def:       Definition{Name: "Haskell", Dir: "haskell", Run: "runghc Main.hs"},
+			want:      Definition{Name: "Haskell", Dir: "haskell", Run: "runghc Main.hs"},
+			assertion: require.NoError,
+		},
+		{
+			name:      "built-in",
+			lang:      "go",
+			def:       Definition{Run: "go run ."},
+			assertion: require.Error,
+			err:       ErrBuiltinRunner,
+		},
+		{
+			name:      "built-in directory",
+			lang:      "golang",
+			def:       Definition{Dir: "Go", Run: "go run ."},
+			assertion: require.Error,
+			err:       ErrDuplicateDir,
+		},
+		{
+			name:      "no run command",
+			lang:      "kt",
+			def:       Definition{Build: "kotlinc main.kt", Run: " "},
+			assertion: require.Error,
+			err:       ErrInvalidDefinition,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Register(tt.lang, tt.def)
+
+			tt.assertion(t, err)
+
+			if tt.err != nil {
+				require.ErrorIs(t, err, tt.err)
+				return
+			}
+
+			lang := strings.ToLower(tt.lang)
+			t.Cleanup(func() { unregister(lang) })
+
+			got, ok := Lookup(tt.lang)
+			require.True(t, ok)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.want.Name, Available[lang]("").String())
+		})
+	}
+}
+
+// unregister removes a configured runner.
+func unregister(lang string) {
+	delete(dirs, strings.ToLower(definitions[lang].Dir))
+	delete(definitions, lang)
+	delete(Available, lang)
+}
+
+func TestRegister_duplicateDir(t *testing.T) {
+	require.NoError(t, Register("hs", Definition{Dir: "haskell", Run: "runghc Main.hs"}))
+	t.Cleanup(func() { unregister("hs") })
+
+	err := Register("ghc", Definition{Dir: "Haskell", Run: "runghc Main.hs"})
+	require.ErrorIs(t, err, ErrDuplicateDir)
+
+	_, ok := Lookup("ghc")
+	assert.False(t, ok)
+
+	// a runner may be registered again with a new directory, freeing the old one
+	require.NoError(t, Register("hs", Definition{Dir: "hs", Run: "runghc Main.hs"}))
+	require.NoError(t, Register("ghc", Definition{Dir: "haskell", Run: "runghc Main.hs"}))
+	t.Cleanup(func() { unregister("ghc") })
+
+	lang, ok := LanguageForDir("haskell")
+	assert.True(t, ok)
+	assert.Equal(t, "ghc", lang)
+}
+
+func TestLanguageForDir(t *testing.T) {
+	require.NoError(t, Register("hs", Definition{Dir: "haskell", Run: "runghc Main.hs"}))
+	t.Cleanup(func() { unregister("hs") })
+
+	tests := []struct {
+		dir    string
+		want   string
+		wantOk bool
+	}{
+		{"go", "go", true},
+		{"PY", "py", true},
+		{"haskell", "hs", true},
+		{"hs", "", false},
+		{"notes", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.dir, func(t *testing.T) {
+			got, ok := LanguageForDir(tt.dir)
+
+			assert.Equal(t, tt.wantOk, ok)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_genericRunner(t *testing.T) {
+	tmplDir := t.TempDir()
+	exDir := t.TempDir()
+
+	wrapper := filepath.Join(tmplDir, "wrapper.sh.tmpl")
+	require.NoError(t, os.WriteFile(wrapper, []byte(`read -r task
+echo "debug from {{ .Dir }}"
+case "$task" in
+*'"input_id":"in-1"'*) echo '{"task_id":"Solve.1","ok":true,"output":"42","duration":0.5}' ;;
+*) echo '{"task_id":"Solve.1","ok":false,"output":"no input id"}' ;;
+esac
+`), 0o600))
+
+	g := newGenericRunner(Definition{
+		Name:    "Shell",
+		Dir:     "sh",
+		Build:   "cp $WRAPPER $WORKSPACE/built.sh",
+		Run:     "sh $WORKSPACE/built.sh",
+		Wrapper: wrapper,
+	}, exDir).(*genericRunner)
+
+	require.NoError(t, g.Start())
+
+	got, err := g.Run(&Task{TaskID: "Solve.1", Part: PartOne, Input: "input", InputID: "in-1"})
+	require.NoError(t, err)
+	assert.Equal(t, &Result{TaskID: "Solve.1", Ok: true, Output: "42", Duration: 0.5}, got)
+
+	require.NoError(t, g.Stop())
+
+	entries, err := os.ReadDir(exDir)
+	require.NoError(t, err)
+	assert.Empty(t, entries, "nothing should be written to the exercise directory")
+
+	assert.FileExists(t, filepath.Join(g.workspace, "wrapper.sh"))
+	require.NoError(t, g.Cleanup())
+	assert.NoDirExists(t, g.workspace)
+	assert.Equal(t, "Shell", g.String())
+}
+
+func Test_genericRunner_StartFailed(t *testing.T) {
+	g := newGenericRunner(Definition{
+		Name:  "Shell",
+		Dir:   "sh",
+		Build: "touch $WORKSPACE/partial",
+		Run:   "elf-test-missing-command",
+	}, t.TempDir()).(*genericRunner)
+
+	workspaceMux.Lock()
+	before := len(workspaces)
+	workspaceMux.Unlock()
+
+	require.Error(t, g.Start())
+
+	workspaceMux.Lock()
+	after := len(workspaces)
+	workspaceMux.Unlock()
+
+	assert.Empty(t, g.workspace)
+	assert.Equal(t, before, after, "workspace of a failed start should be removed")
+	require.NoError(t, g.Cleanup())
+}
+
+func TestNameOf(t *testing.T) {
+	require.NoError(t, Register("hs", Definition{Name: "Haskell", Dir: "haskell", Run: "runghc Main.hs"}))
+	t.Cleanup(func() { unregister("hs") })
+
+	// names match those of unstarted runners
+	for lang, newRunner := range builtins {
+		got, ok := NameOf(lang)
+		assert.True(t, ok, lang)
+		assert.Equal(t, newRunner("").String(), got, lang)
+	}
+
+	got, ok := NameOf("HS")
+	assert.True(t, ok)
+	assert.Equal(t, "Haskell", got)
+
+	_, ok = NameOf("notes")
+	assert.False(t, ok)
+}
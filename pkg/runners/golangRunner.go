@@ -3,75 +3,103 @@ package runners
 import (
 	"bytes"
 	"context"
+	"crypto/sha256"
 	_ "embed"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
 	"io"
 	"log/slog"
 	"os"
 	"os/exec"
+	"path"
 	"path/filepath"
 	"runtime"
+	"slices"
 	"strings"
-	"syscall"
 	"text/template"
 	"time"
 )
 
-var project string
-
 const (
-	goRunnerName                    string = "Go"
-	golangInstallation              string = "go"
-	golangWrapperFilename           string = "runtime-wrapper.go"
-	golangWrapperExecutableFilename string = "runtime-wrapper"
-	golangBuildpathBase             string = "github.com/asphaltbuffet/elf/exercises/%s/%s"
+	goRunnerName          string = "Go"
+	golangInstallation    string = "go"
+	golangWrapperFilename string = "runtime-wrapper.go"
 )
 
+var ErrNoModule = errors.New("no go.mod found")
+
 type golangRunner struct {
 	dir                string
 	cmd                *exec.Cmd
-	wrapperFilepath    string
 	executableFilepath string
 	stdin              io.WriteCloser
+	restarts           int
+
+	// moduleDir is the root of the module containing the exercise. Go commands are run there
+	// so that a go.work above it is used as well.
+	moduleDir string
+
+	// env and buildFlags are set by variants
+	env        []string
+	buildFlags []string
+
+	// writable lists extra directories its processes may write to when sandboxed
+	writable []string
 }
 
 func newGolangRunner(dir string) Runner {
 	return &golangRunner{
-		dir:                dir,
-		wrapperFilepath:    filepath.Join(dir, golangWrapperFilename),
-		executableFilepath: filepath.Join(dir, golangWrapperExecutableFilename),
+		dir: dir,
 	}
 }
 
+func (g *golangRunner) applyVariant(v Variant) error {
+	if err := v.unsupported(goRunnerName, true, false); err != nil {
+		return err
+	}
+
+	g.env = v.Env
+	g.buildFlags = v.BuildFlags
+
+	return nil
+}
+
+func (g *golangRunner) allowWrite(dir string) {
+	g.writable = append(g.writable, dir)
+}
+
 //go:embed interface/go.tmpl
 var golangInterfaceFile []byte
 
 // Start compiles the exercise code and starts the executable.
+//
+// Executables are cached by a hash of everything that goes into the build, so an exercise is
+// only rebuilt when its sources, the wrapper, or the Go toolchain change.
 func (g *golangRunner) Start() error {
 	slog.LogAttrs(context.TODO(), slog.LevelDebug, "setting up runner",
 		slog.String("dir", g.dir),
 	)
 
-	// windows requires .exe extension
-	if runtime.GOOS == "windows" {
-		g.executableFilepath += ".exe"
+	importPath, err := g.importPath()
+	if err != nil {
+		return err
 	}
 
-	project = getModuleName()
-
 	slog.LogAttrs(context.TODO(), slog.LevelDebug, "paths created",
 		slog.String("dir", g.dir),
-		slog.String("project", "project"),
+		slog.String("module", g.moduleDir),
+		slog.String("importPath", importPath),
 	)
 
-	tokens := strings.Split(filepath.ToSlash(g.dir), "/")
-	buildPath := filepath.Join(tokens[len(tokens)-3:]...)
-
-	// determine package import path
-	// should be like: "github.com/asphaltbuffet/advent-of-code/exercises/2015/01-notQuiteLisp/go"
-	importPath := filepath.Join(project, buildPath, "go")
+	hasParse, err := golangHasParse(filepath.Join(g.dir, "go"))
+	if err != nil {
+		return err
+	}
 
 	// generate wrapper code from template
 	var wrapperContent []byte
@@ -79,7 +107,10 @@ func (g *golangRunner) Start() error {
 		tpl := template.Must(template.New("").Parse(string(golangInterfaceFile)))
 		b := new(bytes.Buffer)
 
-		err := tpl.Execute(b, struct{ ImportPath string }{importPath})
+		err := tpl.Execute(b, struct {
+			ImportPath string
+			Parse      bool
+		}{importPath, hasParse})
 		if err != nil {
 			return err
 		}
@@ -87,36 +118,109 @@ func (g *golangRunner) Start() error {
 		wrapperContent = b.Bytes()
 	}
 
+	if settings.GoModTidy {
+		stderrBuffer := new(bytes.Buffer)
+
+		tidycmd := exec.Command(golangInstallation, "mod", "tidy")
+		tidycmd.Dir = g.moduleDir
+		tidycmd.Env = withEnv(g.env)
+
+		tidycmd.Stderr = stderrBuffer
+		if err := tidycmd.Run(); err != nil {
+			return fmt.Errorf("tidy failed: %w: %s", err, stderrBuffer.String())
+		}
+	}
+
+	key, err := g.buildKey(importPath, wrapperContent)
+	if err != nil {
+		return err
+	}
+
+	cacheDir, err := golangCacheDir()
+	if err != nil {
+		return err
+	}
+
+	g.executableFilepath = filepath.Join(cacheDir, key)
+
+	// windows requires .exe extension
+	if runtime.GOOS == "windows" {
+		g.executableFilepath += ".exe"
+	}
+
+	if _, err = os.Stat(g.executableFilepath); err == nil {
+		slog.LogAttrs(context.TODO(), slog.LevelDebug, "using cached runner",
+			slog.String("executable", g.executableFilepath),
+			slog.String("importPath", importPath),
+		)
+
+		// the modification time records the last use so the executable isn't pruned
+		now := time.Now()
+		_ = os.Chtimes(g.executableFilepath, now, now)
+	} else {
+		if err = g.build(wrapperContent, importPath); err != nil {
+			return err
+		}
+
+		pruneGolangCache(cacheDir, time.Now().Add(-golangCacheMaxAge))
+	}
+
+	// run executable for exercise (wrapped)
+
+	g.cmd = exec.Command(g.executableFilepath)
+	g.cmd.Dir = g.dir
+	g.cmd.Env = withEnv(g.env)
+
+	stdin, err := setupBuffers(g.cmd)
+	if err != nil {
+		return err
+	}
+
+	g.stdin = stdin
+
+	return startProcess(g.cmd, g.writable...)
+}
+
+// build compiles the wrapped exercise into the cached executable path. The wrapper is staged
+// in a temporary workspace that is removed once the build finishes.
+func (g *golangRunner) build(wrapperContent []byte, importPath string) error {
+	workspace, err := newWorkspace()
+	if err != nil {
+		return fmt.Errorf("creating workspace: %w", err)
+	}
+
+	defer removeWorkspace(workspace) //nolint:errcheck // best effort
+
 	// write wrapped code
-	if err := os.WriteFile(g.wrapperFilepath, wrapperContent, 0o600); err != nil {
+	wrapperFilepath := filepath.Join(workspace, golangWrapperFilename)
+	if err = os.WriteFile(wrapperFilepath, wrapperContent, 0o600); err != nil {
 		return err
 	}
 
+	// build to a temporary name so concurrent runs never start a partial executable
+	tmpFilepath := fmt.Sprintf("%s.%d.tmp", g.executableFilepath, os.Getpid())
+
 	slog.LogAttrs(context.Background(), slog.LevelDebug, "building runner",
-		slog.String("wrapper", g.wrapperFilepath),
+		slog.String("wrapper", wrapperFilepath),
 		slog.String("executable", g.executableFilepath),
-		slog.String("buildPath", buildPath),
-		slog.String("project", project),
+		slog.String("module", g.moduleDir),
 		slog.String("importPath", importPath),
 	)
 
 	stderrBuffer := new(bytes.Buffer)
 
-	tidycmd := exec.Command(golangInstallation, "mod", "tidy")
-
-	tidycmd.Stderr = stderrBuffer
-	if err := tidycmd.Run(); err != nil {
-		return fmt.Errorf("tidy failed: %w: %s", err, stderrBuffer.String())
-	}
-
-	//nolint:gosec // no user input
-	cmd := exec.Command(golangInstallation, "build",
-		"-tags", "runtime",
-		"-o", g.executableFilepath,
-		g.wrapperFilepath)
+	args := slices.Concat(
+		[]string{"build", "-tags", "runtime"},
+		g.buildFlags,
+		[]string{"-o", tmpFilepath, wrapperFilepath},
+	)
 
+	//nolint:gosec // build flags are provided by the user
+	cmd := exec.Command(golangInstallation, args...)
+	cmd.Dir = g.moduleDir
+	cmd.Env = withEnv(g.env)
 	cmd.Stderr = stderrBuffer
-	if err := cmd.Run(); err != nil {
+	if err = cmd.Run(); err != nil {
 		return fmt.Errorf("compilation failed: %w: %s", err, stderrBuffer.String())
 	}
 
@@ -124,110 +228,255 @@ func (g *golangRunner) Start() error {
 		return errors.New("compilation failed")
 	}
 
-	absExecPath, err := filepath.Abs(g.executableFilepath)
+	return os.Rename(tmpFilepath, g.executableFilepath)
+}
+
+func (g *golangRunner) Stop() error {
+	return stopProcess(g.cmd, "go")
+}
+
+// Cleanup does nothing; the wrapper is removed after building and the executable is kept in
+// the cache for later runs.
+func (g *golangRunner) Cleanup() error {
+	return nil
+}
+
+func (g *golangRunner) Run(task *Task) (*Result, error) {
+	return runTaskRestarting(task, &g.cmd, &g.stdin, &g.restarts)
+}
+
+// String returns a string representation of the runner type.
+func (g *golangRunner) String() string {
+	return goRunnerName
+}
+
+// importPath returns the import path of the exercise package, which is in the "go"
+// directory of the exercise. The package may be nested at any depth in its module.
+func (g *golangRunner) importPath() (string, error) {
+	pkgDir, err := filepath.Abs(filepath.Join(g.dir, "go"))
 	if err != nil {
-		return err
+		return "", err
 	}
 
-	// run executable for exercise (wrapped)
-
-	g.cmd = exec.Command(absExecPath)
-	cmd.Dir = g.dir
+	g.moduleDir, err = findModuleDir(pkgDir)
+	if err != nil {
+		return "", err
+	}
 
-	stdin, err := setupBuffers(g.cmd)
+	modulePath, err := golangModulePath(g.moduleDir)
 	if err != nil {
-		return err
+		return "", err
 	}
 
-	g.stdin = stdin
+	rel, err := filepath.Rel(g.moduleDir, pkgDir)
+	if err != nil {
+		return "", err
+	}
 
-	return g.cmd.Start()
+	return path.Join(modulePath, filepath.ToSlash(rel)), nil
 }
 
-func (g *golangRunner) Stop() error {
-	const processExitTimeout time.Duration = 5 * time.Second
+// golangHasParse reports whether the exercise package in dir declares a Parse method on
+// Exercise, with a value or pointer receiver. The wrapper then parses each input once and
+// passes the parsed value to the parts.
+func golangHasParse(dir string) (bool, error) {
+	fset := token.NewFileSet()
 
-	if g.cmd == nil || g.cmd.Process == nil {
-		return nil
+	files, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		return false, err
 	}
 
-	// First try to send a SIGTERM.
-	if err := g.cmd.Process.Signal(syscall.SIGTERM); err != nil {
-		return fmt.Errorf("failed to send SIGTERM to go process: %w", err)
+	for _, file := range files {
+		if strings.HasSuffix(file, "_test.go") {
+			continue
+		}
+
+		f, err := parser.ParseFile(fset, file, nil, parser.SkipObjectResolution)
+		if err != nil {
+			return false, fmt.Errorf("parsing %s: %w", file, err)
+		}
+
+		for _, decl := range f.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if !ok || fn.Name.Name != "Parse" || fn.Recv == nil || len(fn.Recv.List) != 1 {
+				continue
+			}
+
+			recv := fn.Recv.List[0].Type
+			if star, ok := recv.(*ast.StarExpr); ok {
+				recv = star.X
+			}
+
+			if ident, ok := recv.(*ast.Ident); ok && ident.Name == "Exercise" {
+				return true, nil
+			}
+		}
 	}
 
-	// Wait for the process to exit, but not forever.
-	done := make(chan error, 1)
-	go func() {
-		_, err := g.cmd.Process.Wait()
-		done <- err
-	}()
+	return false, nil
+}
 
-	// wait up to 5 seconds for the process to exit.
-	select {
-	case <-time.After(processExitTimeout):
-		if err := g.cmd.Process.Kill(); err != nil {
-			return fmt.Errorf("failed to kill go process: %w", err)
+// findModuleDir walks up from dir to the nearest directory containing a go.mod file.
+func findModuleDir(dir string) (string, error) {
+	for d := dir; ; d = filepath.Dir(d) {
+		if _, err := os.Stat(filepath.Join(d, "go.mod")); err == nil {
+			return d, nil
 		}
-	case err := <-done:
-		if err != nil {
-			return fmt.Errorf("failed to stop go process: %w", err)
+
+		if filepath.Dir(d) == d {
+			return "", fmt.Errorf("%w in %s or any parent directory", ErrNoModule, dir)
 		}
 	}
-
-	return nil
 }
 
-func (g *golangRunner) Cleanup() error {
-	var wrapperErr, execErr error
+// golangModulePath returns the path declared in the go.mod file in dir.
+func golangModulePath(dir string) (string, error) {
+	out, err := golangOutput(dir, nil, "mod", "edit", "-json")
+	if err != nil {
+		return "", fmt.Errorf("reading go.mod: %w", err)
+	}
+
+	var mod struct {
+		Module struct{ Path string }
+	}
 
-	if g.wrapperFilepath != "" {
-		wrapperErr = os.Remove(g.wrapperFilepath)
+	if err = json.Unmarshal(out, &mod); err != nil {
+		return "", fmt.Errorf("parsing go.mod: %w", err)
 	}
 
-	if g.executableFilepath != "" {
-		execErr = os.Remove(g.executableFilepath)
+	if mod.Module.Path == "" {
+		return "", fmt.Errorf("%s has no module path", filepath.Join(dir, "go.mod"))
 	}
 
-	return errors.Join(wrapperErr, execErr)
+	return mod.Module.Path, nil
 }
 
-func (g *golangRunner) Run(task *Task) (*Result, error) {
-	taskJSON, err := json.Marshal(task)
+// golangCacheDir returns the directory holding cached Go executables, creating it if needed.
+func golangCacheDir() (string, error) {
+	dir, err := CacheDir()
 	if err != nil {
-		return nil, fmt.Errorf("marshalling task to json: %w", err)
+		return "", err
 	}
 
-	_, err = g.stdin.Write(append(taskJSON, '\n'))
-	if err != nil {
-		return nil, fmt.Errorf("writing task to stdin: %w", err)
+	if err = os.MkdirAll(dir, 0o750); err != nil {
+		return "", fmt.Errorf("creating cache directory: %w", err)
 	}
 
-	r := new(Result)
+	return dir, nil
+}
 
-	if jsonErr := readJSONFromCommand(r, g.cmd); jsonErr != nil {
-		return nil, jsonErr
+// golangCacheMaxAge is how long a cached executable is kept without being used.
+const golangCacheMaxAge = 30 * 24 * time.Hour
+
+// pruneGolangCache removes cached executables, and leftovers of interrupted builds, that
+// haven't been used since before cutoff. It is best effort; failures are only logged.
+func pruneGolangCache(dir string, cutoff time.Time) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		slog.Debug("reading cache directory", slog.String("dir", dir), slog.Any("error", err))
+		return
 	}
 
-	return r, nil
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil || !info.ModTime().Before(cutoff) {
+			continue
+		}
+
+		path := filepath.Join(dir, entry.Name())
+		if err = os.RemoveAll(path); err != nil {
+			slog.Debug("pruning cached executable", slog.String("path", path), slog.Any("error", err))
+			continue
+		}
+
+		slog.Debug("pruned cached executable", slog.String("path", path))
+	}
 }
 
-// String returns a string representation of the runner type.
-func (g *golangRunner) String() string {
-	return goRunnerName
+// golangDepsFormat lists, for each package the exercise depends on, either its module
+// version or its source files. Files are listed for the main module and for modules without a
+// version or replaced by a local directory, since their contents can change; other module
+// versions never do.
+const golangDepsFormat = `{{if not .Standard}}` +
+	`{{if and .Module (not .Module.Main) .Module.Version (not (and .Module.Replace (not .Module.Replace.Version)))}}` +
+	`module {{.Module.Path}}@{{.Module.Version}}{{with .Module.Replace}} => {{.Path}}@{{.Version}}{{end}}{{"\n"}}` +
+	`{{else}}{{$dir := .Dir}}` +
+	`{{range .GoFiles}}file {{$dir}}{{"\x00"}}{{.}}{{"\n"}}{{end}}` +
+	`{{range .CgoFiles}}file {{$dir}}{{"\x00"}}{{.}}{{"\n"}}{{end}}` +
+	`{{range .EmbedFiles}}file {{$dir}}{{"\x00"}}{{.}}{{"\n"}}{{end}}` +
+	`{{end}}{{end}}`
+
+// buildKey returns a hash of the wrapper, the Go toolchain, the build flags, and the sources
+// of every package the exercise depends on.
+func (g *golangRunner) buildKey(importPath string, wrapperContent []byte) (string, error) {
+	h := sha256.New()
+	h.Write(wrapperContent)
+
+	env, err := golangOutput(g.moduleDir, g.env, "env", "GOVERSION", "GOOS", "GOARCH", "GOFLAGS", "CGO_ENABLED")
+	if err != nil {
+		return "", fmt.Errorf("reading go environment: %w", err)
+	}
+
+	h.Write(env)
+
+	for _, flag := range g.buildFlags {
+		h.Write([]byte(flag + "\x00"))
+
+		// a changed profile changes the build even though the flag is the same
+		if profile, ok := strings.CutPrefix(flag, "-pgo="); ok && profile != "auto" && profile != "off" {
+			if !filepath.IsAbs(profile) {
+				profile = filepath.Join(g.moduleDir, profile)
+			}
+
+			data, readErr := os.ReadFile(profile)
+			if readErr != nil {
+				return "", fmt.Errorf("reading profile: %w", readErr)
+			}
+
+			h.Write(data)
+		}
+	}
+
+	deps, err := golangOutput(g.moduleDir, g.env, "list", "-tags", "runtime", "-deps", "-f", golangDepsFormat, importPath)
+	if err != nil {
+		return "", fmt.Errorf("listing exercise dependencies: %w", err)
+	}
+
+	for _, line := range strings.Split(string(deps), "\n") {
+		dir, file, ok := strings.Cut(strings.TrimPrefix(line, "file "), "\x00")
+		if !ok {
+			h.Write([]byte(line))
+			continue
+		}
+
+		data, readErr := os.ReadFile(filepath.Join(dir, file))
+		if readErr != nil {
+			return "", readErr
+		}
+
+		h.Write([]byte(file))
+		h.Write(data)
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
 }
 
-func getModuleName() string {
+// golangOutput runs a go command in dir, with any extra environment variables, and returns
+// its output.
+func golangOutput(dir string, env []string, args ...string) ([]byte, error) {
 	errBuf := new(bytes.Buffer)
 	outBuf := new(bytes.Buffer)
 
-	cmd := exec.Command(golangInstallation, "list", "-m")
+	cmd := exec.Command(golangInstallation, args...)
+	cmd.Dir = dir
+	cmd.Env = withEnv(env)
 	cmd.Stdout = outBuf
 	cmd.Stderr = errBuf
 
 	if err := cmd.Run(); err != nil {
-		panic("failed to get module name: " + errBuf.String())
+		return nil, fmt.Errorf("%w: %s", err, strings.TrimSpace(errBuf.String()))
 	}
 
-	return strings.Trim(outBuf.String(), "\n")
+	return outBuf.Bytes(), nil
 }
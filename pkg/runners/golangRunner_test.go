@@ -4,6 +4,7 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -25,11 +26,9 @@ func Test_newGolangRunner(t *testing.T) {
 				dir: filepath.Join("testdata", "2015", "01-testDayOne", "go"),
 			},
 			want: &golangRunner{
-				dir:                filepath.Join("testdata", "2015", "01-testDayOne", "go"),
-				cmd:                nil,
-				wrapperFilepath:    filepath.Join("testdata", "2015", "01-testDayOne", "go", "runtime-wrapper.go"),
-				executableFilepath: filepath.Join("testdata", "2015", "01-testDayOne", "go", "runtime-wrapper"),
-				stdin:              nil,
+				dir:   filepath.Join("testdata", "2015", "01-testDayOne", "go"),
+				cmd:   nil,
+				stdin: nil,
 			},
 		},
 	}
@@ -42,66 +41,215 @@ func Test_newGolangRunner(t *testing.T) {
 }
 
 func Test_golangRunner_Cleanup(t *testing.T) {
-	tf, err := os.MkdirTemp("", "test-go")
+	exDir := t.TempDir()
+	executable := filepath.Join(t.TempDir(), "cached-executable")
+	require.NoError(t, os.WriteFile(executable, []byte("fake binary"), 0o600))
+
+	g := &golangRunner{dir: exDir, executableFilepath: executable}
+
+	require.NoError(t, g.Cleanup())
+	assert.FileExists(t, executable, "cached executable should be kept")
+}
+
+func Test_golangCacheDir(t *testing.T) {
+	base := t.TempDir()
+
+	Configure(Settings{CacheDir: base})
+	t.Cleanup(func() { Configure(Settings{}) })
+
+	got, err := golangCacheDir()
+	require.NoError(t, err)
+
+	assert.Equal(t, filepath.Join(base, "go"), got)
+	assert.DirExists(t, got)
+}
+
+func Test_golangRunner_buildKey(t *testing.T) {
+	const importPath = "github.com/asphaltbuffet/elf/pkg/tasks"
+
+	g := &golangRunner{moduleDir: "."}
+
+	key, err := g.buildKey(importPath, []byte("wrapper"))
+	require.NoError(t, err)
+	assert.Len(t, key, 64)
+
+	again, err := g.buildKey(importPath, []byte("wrapper"))
+	require.NoError(t, err)
+	assert.Equal(t, key, again, "unchanged inputs should give the same key")
+
+	changed, err := g.buildKey(importPath, []byte("changed wrapper"))
 	require.NoError(t, err)
+	assert.NotEqual(t, key, changed, "changed wrapper should give a new key")
+
+	flagged := &golangRunner{moduleDir: ".", buildFlags: []string{"-gcflags=-B"}}
+
+	withFlags, err := flagged.buildKey(importPath, []byte("wrapper"))
+	require.NoError(t, err)
+	assert.NotEqual(t, key, withFlags, "build flags should give a new key")
+
+	profile := filepath.Join(t.TempDir(), "default.pgo")
+	require.NoError(t, os.WriteFile(profile, []byte("profile one"), 0o600))
+
+	pgo := &golangRunner{moduleDir: ".", buildFlags: []string{"-pgo=" + profile}}
+
+	withProfile, err := pgo.buildKey(importPath, []byte("wrapper"))
+	require.NoError(t, err)
+
+	require.NoError(t, os.WriteFile(profile, []byte("profile two"), 0o600))
+
+	withNewProfile, err := pgo.buildKey(importPath, []byte("wrapper"))
+	require.NoError(t, err)
+	assert.NotEqual(t, withProfile, withNewProfile, "changed profile should give a new key")
+
+	_, err = g.buildKey("github.com/asphaltbuffet/elf/not/a/package", []byte("wrapper"))
+	require.Error(t, err)
+}
+
+func Test_golangRunner_buildKey_replacedModule(t *testing.T) {
+	root := t.TempDir()
+	mainDir := filepath.Join(root, "solutions")
+	libDir := filepath.Join(root, "lib")
+
+	writeFile := func(path, content string) {
+		t.Helper()
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o750))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	}
+
+	writeFile(filepath.Join(libDir, "go.mod"), "module example.com/lib\n")
+	writeFile(filepath.Join(libDir, "lib.go"), "package lib\n\nconst Answer = 42\n")
+	writeFile(filepath.Join(mainDir, "go.mod"),
+		"module example.com/solutions\n\nrequire example.com/lib v0.0.0\n\nreplace example.com/lib => ../lib\n")
+	writeFile(filepath.Join(mainDir, "main.go"),
+		"package solutions\n\nimport \"example.com/lib\"\n\nvar Answer = lib.Answer\n")
+
+	g := &golangRunner{moduleDir: mainDir}
+
+	key, err := g.buildKey("example.com/solutions", []byte("wrapper"))
+	require.NoError(t, err)
+
+	writeFile(filepath.Join(libDir, "lib.go"), "package lib\n\nconst Answer = 43\n")
+
+	changed, err := g.buildKey("example.com/solutions", []byte("wrapper"))
+	require.NoError(t, err)
+	assert.NotEqual(t, key, changed, "edits to a locally replaced module should give a new key")
+}
 
-	defer require.NoError(t, os.RemoveAll(tf))
+func Test_pruneGolangCache(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Now()
 
-	exDir := filepath.Join(tf, "2015", "01-testDayOne", "go")
-	require.NoError(t, os.MkdirAll(exDir, 0o750))
+	for name, age := range map[string]time.Duration{
+		"fresh":     time.Hour,
+		"stale":     golangCacheMaxAge + time.Hour,
+		"stale.tmp": golangCacheMaxAge + time.Hour,
+	} {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(path, []byte("fake binary"), 0o600))
+		require.NoError(t, os.Chtimes(path, now.Add(-age), now.Add(-age)))
+	}
+
+	pruneGolangCache(dir, now.Add(-golangCacheMaxAge))
+
+	assert.FileExists(t, filepath.Join(dir, "fresh"))
+	assert.NoFileExists(t, filepath.Join(dir, "stale"))
+	assert.NoFileExists(t, filepath.Join(dir, "stale.tmp"))
+}
+
+func Test_golangRunner_importPath(t *testing.T) {
+	root := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(root, "go.mod"), []byte("module example.com/solutions\n"), 0o600))
 
 	tests := []struct {
-		name            string
-		g               *golangRunner
-		writeWrapper    bool
-		writeExecutable bool
-		assertion       assert.ErrorAssertionFunc
-		err             error
+		name      string
+		dir       string
+		want      string
+		assertion assert.ErrorAssertionFunc
 	}{
 		{
-			name: "all files exist",
-			g: &golangRunner{
-				dir:                exDir,
-				cmd:                nil,
-				wrapperFilepath:    filepath.Join(exDir, golangWrapperFilename),
-				executableFilepath: filepath.Join(exDir, golangWrapperExecutableFilename),
-				stdin:              nil,
-			},
-			writeWrapper:    true,
-			writeExecutable: true,
-			assertion:       assert.NoError,
+			name:      "advent layout",
+			dir:       filepath.Join(root, "exercises", "2015", "01-notQuiteLisp"),
+			want:      "example.com/solutions/exercises/2015/01-notQuiteLisp/go",
+			assertion: assert.NoError,
 		},
 		{
-			name: "no files exist",
-			g: &golangRunner{
-				dir:                exDir,
-				cmd:                nil,
-				wrapperFilepath:    filepath.Join(exDir, golangWrapperFilename),
-				executableFilepath: filepath.Join(exDir, golangWrapperExecutableFilename),
-				stdin:              nil,
-			},
-			writeWrapper:    false,
-			writeExecutable: false,
-			assertion:       assert.Error,
+			name:      "deeper nesting",
+			dir:       filepath.Join(root, "puzzles", "advent", "2015", "day01"),
+			want:      "example.com/solutions/puzzles/advent/2015/day01/go",
+			assertion: assert.NoError,
+		},
+		{
+			name:      "exercise at module root",
+			dir:       root,
+			want:      "example.com/solutions/go",
+			assertion: assert.NoError,
+		},
+		{
+			name:      "no module",
+			dir:       filepath.Join(t.TempDir(), "2015", "01-notQuiteLisp"),
+			want:      "",
+			assertion: assert.Error,
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if tt.writeWrapper {
-				require.NoError(t, os.WriteFile(tt.g.wrapperFilepath, []byte("fake test data"), 0o600))
-			}
-			if tt.writeExecutable {
-				require.NoError(t, os.WriteFile(tt.g.executableFilepath, []byte("fake binary"), 0o600))
+			g := &golangRunner{dir: tt.dir}
+
+			got, err := g.importPath()
+
+			tt.assertion(t, err)
+			assert.Equal(t, tt.want, got)
+
+			if err == nil {
+				assert.Equal(t, root, g.moduleDir)
 			}
+		})
+	}
+}
 
-			tErr := tt.g.Cleanup()
+func Test_golangHasParse(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		want   bool
+	}{
+		{
+			name:   "no parse",
+			source: "package ex\n\ntype Exercise struct{}\n\nfunc (e Exercise) One(s string) (any, error) { return s, nil }\n",
+			want:   false,
+		},
+		{
+			name:   "parse method",
+			source: "package ex\n\ntype Exercise struct{}\n\nfunc (Exercise) Parse(s string) ([]int, error) { return nil, nil }\n",
+			want:   true,
+		},
+		{
+			name:   "parse method with pointer receiver",
+			source: "package ex\n\ntype Exercise struct{}\n\nfunc (e *Exercise) Parse(s string) ([]int, error) { return nil, nil }\n",
+			want:   true,
+		},
+		{
+			name:   "parse function",
+			source: "package ex\n\nfunc Parse(s string) ([]int, error) { return nil, nil }\n",
+			want:   false,
+		},
+		{
+			name:   "parse on another type",
+			source: "package ex\n\ntype grid struct{}\n\nfunc (grid) Parse(s string) error { return nil }\n",
+			want:   false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			require.NoError(t, os.WriteFile(filepath.Join(dir, "exercise.go"), []byte(tt.source), 0o600))
 
-			assert.NoFileExists(t, tt.g.wrapperFilepath)
-			assert.NoFileExists(t, tt.g.executableFilepath)
-			require.DirExists(t, tt.g.dir)
+			got, err := golangHasParse(dir)
 
-			tt.assertion(t, tErr)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
 		})
 	}
 }
@@ -0,0 +1,43 @@
+"""Helpers for exercises run by elf.
+
+Import them in an exercise with ``from elf import progress``.
+"""
+
+import json
+import os
+import sys
+import time
+
+# the least time between progress reports, so reporting from a hot loop does not slow the
+# task down
+PROGRESS_INTERVAL = 0.1
+
+_last_progress = None
+
+
+def progress(done: int = 0, total: int = 0, fraction: float = 0.0) -> None:
+    """Reports how far the running task has come, shown by elf as a progress bar.
+
+    Pass either the number of completed steps and the total number of steps, or the completed
+    fraction of the task from 0 to 1. A total of zero means the number of steps is not known.
+
+    Reports are dropped if they come too quickly after the previous one, except for the last
+    step.
+    """
+    global _last_progress
+
+    final = (total > 0 and done >= total) or fraction >= 1
+    now = time.monotonic()
+    if not final and _last_progress is not None and now - _last_progress < PROGRESS_INTERVAL:
+        return
+
+    _last_progress = now
+
+    report = {"done": done, "total": total} if total > 0 or done > 0 else {"fraction": fraction}
+    message = {"progress": report}
+
+    task_id = os.environ.get("ELF_TASK_ID")
+    if task_id:
+        message["task_id"] = task_id
+
+    print(json.dumps(message), file=sys.stdout, flush=True)
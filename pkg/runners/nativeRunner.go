@@ -0,0 +1,243 @@
+package runners
+
+import (
+	"bytes"
+	"context"
+	_ "embed"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+)
+
+const nativeWrapperExecutableFilename string = "runtime-wrapper"
+
+//go:embed interface/c.tmpl
+var cInterfaceFile []byte
+
+//go:embed interface/cpp.tmpl
+var cppInterfaceFile []byte
+
+// nativeLanguage describes how to compile a language to a native executable.
+type nativeLanguage struct {
+	name            string
+	srcDir          string
+	extensions      []string
+	wrapperFilename string
+	wrapper         []byte
+
+	// toolchain returns the configured compiler command and flags, which default to
+	// defaultCompiler and defaultFlags.
+	toolchain       func(s Settings) (string, []string)
+	defaultCompiler string
+	defaultFlags    []string
+
+	// libs are linked after the exercise sources
+	libs []string
+}
+
+var (
+	cLanguage = &nativeLanguage{
+		name:            "C",
+		srcDir:          "c",
+		extensions:      []string{".c"},
+		wrapperFilename: "runtime-wrapper.c",
+		wrapper:         cInterfaceFile,
+		toolchain:       func(s Settings) (string, []string) { return s.CCompiler, s.CFlags },
+		defaultCompiler: "cc",
+		defaultFlags:    []string{"-O2"},
+		libs:            []string{"-lm"},
+	}
+
+	cppLanguage = &nativeLanguage{
+		name:            "C++",
+		srcDir:          "cpp",
+		extensions:      []string{".cpp", ".cc", ".cxx"},
+		wrapperFilename: "runtime-wrapper.cpp",
+		wrapper:         cppInterfaceFile,
+		toolchain:       func(s Settings) (string, []string) { return s.CXXCompiler, s.CXXFlags },
+		defaultCompiler: "c++",
+		defaultFlags:    []string{"-O2"},
+	}
+)
+
+// nativeRunner compiles C or C++ exercise sources with a wrapper and runs the executable.
+type nativeRunner struct {
+	lang      *nativeLanguage
+	dir       string
+	cmd       *exec.Cmd
+	stdin     io.WriteCloser
+	restarts  int
+	workspace string
+
+	// env and buildFlags are set by variants
+	env        []string
+	buildFlags []string
+
+	// writable lists extra directories its processes may write to when sandboxed
+	writable []string
+}
+
+func newNativeRunner(lang *nativeLanguage, dir string) *nativeRunner {
+	return &nativeRunner{
+		lang: lang,
+		dir:  dir,
+	}
+}
+
+func newCRunner(dir string) Runner {
+	return newNativeRunner(cLanguage, dir)
+}
+
+func newCppRunner(dir string) Runner {
+	return newNativeRunner(cppLanguage, dir)
+}
+
+func (n *nativeRunner) applyVariant(v Variant) error {
+	if err := v.unsupported(n.lang.name, true, false); err != nil {
+		return err
+	}
+
+	n.env = v.Env
+	n.buildFlags = v.BuildFlags
+
+	return nil
+}
+
+func (n *nativeRunner) allowWrite(dir string) {
+	n.writable = append(n.writable, dir)
+}
+
+// Start compiles the exercise sources and starts the executable.
+//
+// The compiler and flags come from the settings. Variant build flags are added after the
+// flags, so they can override them.
+func (n *nativeRunner) Start() (err error) {
+	sources, err := n.sources()
+	if err != nil {
+		return err
+	}
+
+	workspace, err := newWorkspace()
+	if err != nil {
+		return fmt.Errorf("creating workspace: %w", err)
+	}
+
+	n.workspace = workspace
+
+	// runners that fail to start are not cleaned up
+	defer func() {
+		if err != nil {
+			_ = removeWorkspace(n.workspace)
+			n.workspace = ""
+		}
+	}()
+
+	wrapperFilepath := filepath.Join(n.workspace, n.lang.wrapperFilename)
+	if err = os.WriteFile(wrapperFilepath, n.lang.wrapper, 0o600); err != nil {
+		return err
+	}
+
+	executableFilepath := filepath.Join(n.workspace, nativeWrapperExecutableFilename)
+
+	// windows requires .exe extension
+	if runtime.GOOS == "windows" {
+		executableFilepath += ".exe"
+	}
+
+	compiler, flags := n.lang.toolchain(settings)
+
+	// the compiler may be a command with arguments, e.g. "ccache gcc"
+	command := strings.Fields(compiler)
+	if len(command) == 0 {
+		command = []string{n.lang.defaultCompiler}
+	}
+
+	if len(flags) == 0 {
+		flags = n.lang.defaultFlags
+	}
+
+	args := slices.Concat(
+		command[1:],
+		flags,
+		n.buildFlags,
+		[]string{"-I", n.lang.srcDir, "-o", executableFilepath, wrapperFilepath},
+		sources,
+		n.lang.libs,
+	)
+
+	slog.LogAttrs(context.TODO(), slog.LevelDebug, "building runner",
+		slog.String("dir", n.dir),
+		slog.String("compiler", command[0]),
+		slog.Any("args", args),
+	)
+
+	stderrBuffer := new(bytes.Buffer)
+
+	//nolint:gosec // compiler is provided by the user
+	cmd := exec.Command(command[0], args...)
+	cmd.Dir = n.dir
+	cmd.Env = withEnv(n.env)
+	cmd.Stderr = stderrBuffer
+
+	if err = cmd.Run(); err != nil {
+		return fmt.Errorf("compilation failed: %w: %s", err, stderrBuffer.String())
+	}
+
+	n.cmd = exec.Command(executableFilepath)
+	n.cmd.Dir = n.dir
+	n.cmd.Env = withEnv(n.env)
+
+	stdin, err := setupBuffers(n.cmd)
+	if err != nil {
+		return err
+	}
+
+	n.stdin = stdin
+
+	return startProcess(n.cmd, n.writable...)
+}
+
+// sources returns the exercise source files, relative to the exercise directory.
+func (n *nativeRunner) sources() ([]string, error) {
+	entries, err := os.ReadDir(filepath.Join(n.dir, n.lang.srcDir))
+	if err != nil {
+		return nil, fmt.Errorf("reading %s sources: %w", n.lang.name, err)
+	}
+
+	var sources []string
+
+	for _, e := range entries {
+		if !e.IsDir() && slices.Contains(n.lang.extensions, strings.ToLower(filepath.Ext(e.Name()))) {
+			sources = append(sources, filepath.Join(n.lang.srcDir, e.Name()))
+		}
+	}
+
+	if len(sources) == 0 {
+		return nil, fmt.Errorf("no %s sources in %s", n.lang.name, filepath.Join(n.dir, n.lang.srcDir))
+	}
+
+	return sources, nil
+}
+
+func (n *nativeRunner) Stop() error {
+	return stopProcess(n.cmd, n.lang.name)
+}
+
+func (n *nativeRunner) Cleanup() error {
+	return removeWorkspace(n.workspace)
+}
+
+func (n *nativeRunner) Run(task *Task) (*Result, error) {
+	return runTaskRestarting(task, &n.cmd, &n.stdin, &n.restarts)
+}
+
+// String returns a string representation of the runner type.
+func (n *nativeRunner) String() string {
+	return n.lang.name
+}
@@ -0,0 +1,157 @@
+package runners
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_newNativeRunners(t *testing.T) {
+	dir := filepath.Join("testdata", "2015", "01-testDayOne")
+
+	tests := []struct {
+		name   string
+		create RunnerCreator
+		want   Runner
+	}{
+		{
+			name:   "c",
+			create: newCRunner,
+			want: &nativeRunner{
+				lang: cLanguage,
+				dir:  dir,
+			},
+		},
+		{
+			name:   "c++",
+			create: newCppRunner,
+			want: &nativeRunner{
+				lang: cppLanguage,
+				dir:  dir,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.create(dir))
+		})
+	}
+}
+
+func Test_nativeRunner_sources(t *testing.T) {
+	exDir := t.TempDir()
+
+	for _, name := range []string{"a.c", "b.c", "c.h", "d.cpp", "e.cc", "notes.txt"} {
+		require.NoError(t, os.MkdirAll(filepath.Join(exDir, "c"), 0o750))
+		require.NoError(t, os.WriteFile(filepath.Join(exDir, "c", name), nil, 0o600))
+	}
+
+	require.NoError(t, os.MkdirAll(filepath.Join(exDir, "cpp"), 0o750))
+	require.NoError(t, os.WriteFile(filepath.Join(exDir, "cpp", "exercise.cxx"), nil, 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(exDir, "cpp", "util.hpp"), nil, 0o600))
+
+	tests := []struct {
+		name      string
+		n         *nativeRunner
+		want      []string
+		assertion assert.ErrorAssertionFunc
+	}{
+		{
+			name:      "c",
+			n:         newNativeRunner(cLanguage, exDir),
+			want:      []string{filepath.Join("c", "a.c"), filepath.Join("c", "b.c")},
+			assertion: assert.NoError,
+		},
+		{
+			name:      "c++",
+			n:         newNativeRunner(cppLanguage, exDir),
+			want:      []string{filepath.Join("cpp", "exercise.cxx")},
+			assertion: assert.NoError,
+		},
+		{
+			name:      "missing directory",
+			n:         newNativeRunner(cLanguage, filepath.Join(exDir, "missing")),
+			want:      nil,
+			assertion: assert.Error,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.n.sources()
+
+			tt.assertion(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_nativeRunner_Cleanup(t *testing.T) {
+	workspace, err := newWorkspace()
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(workspace, "runtime-wrapper"), []byte("fake binary"), 0o600))
+
+	n := &nativeRunner{lang: cLanguage, workspace: workspace}
+
+	require.NoError(t, n.Cleanup())
+	assert.NoDirExists(t, workspace)
+
+	assert.NoError(t, (&nativeRunner{lang: cLanguage}).Cleanup(), "runner that was not started")
+}
+
+func Test_nativeRunner_String(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "C", newCRunner("").String())
+	assert.Equal(t, "C++", newCppRunner("").String())
+}
+
+func Test_nativeRunner_Start_toolchain(t *testing.T) {
+	exDir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(exDir, "c"), 0o750))
+	require.NoError(t, os.WriteFile(filepath.Join(exDir, "c", "exercise.c"), nil, 0o600))
+
+	argsFile := filepath.Join(t.TempDir(), "args")
+	script := filepath.Join(t.TempDir(), "fake-cc.sh")
+	require.NoError(t, os.WriteFile(script, []byte(`echo "$@" > `+argsFile+"\nexit 1\n"), 0o600))
+
+	tests := []struct {
+		name       string
+		settings   Settings
+		buildFlags []string
+		want       []string
+	}{
+		{
+			name:     "default flags",
+			settings: Settings{CCompiler: "sh " + script},
+			want:     []string{"-O2", "-I", "c"},
+		},
+		{
+			name:       "configured flags before variant flags",
+			settings:   Settings{CCompiler: "sh " + script, CFlags: []string{"-O3", "-g"}},
+			buildFlags: []string{"-march=native"},
+			want:       []string{"-O3", "-g", "-march=native", "-I", "c"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			Configure(tt.settings)
+			t.Cleanup(func() { Configure(Settings{}) })
+
+			n := newNativeRunner(cLanguage, exDir)
+			n.buildFlags = tt.buildFlags
+
+			require.ErrorContains(t, n.Start(), "compilation failed")
+
+			got, err := os.ReadFile(argsFile)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, strings.Fields(string(got))[:len(tt.want)])
+		})
+	}
+}
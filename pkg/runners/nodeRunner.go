@@ -0,0 +1,229 @@
+package runners
+
+import (
+	"bytes"
+	"context"
+	_ "embed"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"text/template"
+)
+
+const (
+	javaScriptRunnerName string = "JavaScript"
+	typeScriptRunnerName string = "TypeScript"
+	nodeInstallation     string = "node"
+	nodeWrapperFilename  string = "runtime-wrapper.js"
+	nodeBuildDirname     string = "runtime-build"
+	typeScriptEntrypoint string = "ts/index.ts"
+	defaultTranspileCmd  string = "tsc"
+)
+
+// Placeholders in the transpiler command for the output directory and the entry file.
+const (
+	transpileOutDir = "{outdir}"
+	transpileEntry  = "{entry}"
+)
+
+// tsconfigFiles are where a TypeScript project configuration is looked for, relative to the
+// exercise directory.
+var tsconfigFiles = []string{"ts/tsconfig.json", "tsconfig.json"}
+
+type nodeRunner struct {
+	cmd       *exec.Cmd
+	dir       string
+	stdin     io.WriteCloser
+	restarts  int
+	workspace string
+
+	// typescript exercises are transpiled into the workspace before starting
+	typescript bool
+
+	// env is set by variants
+	env []string
+
+	// writable lists extra directories its processes may write to when sandboxed
+	writable []string
+}
+
+func newJavaScriptRunner(dir string) Runner {
+	return &nodeRunner{
+		dir: dir,
+	}
+}
+
+func newTypeScriptRunner(dir string) Runner {
+	return &nodeRunner{
+		dir:        dir,
+		typescript: true,
+	}
+}
+
+func (n *nodeRunner) applyVariant(v Variant) error {
+	if err := v.unsupported(n.String(), false, false); err != nil {
+		return err
+	}
+
+	n.env = v.Env
+
+	return nil
+}
+
+func (n *nodeRunner) allowWrite(dir string) {
+	n.writable = append(n.writable, dir)
+}
+
+//go:embed interface/node.tmpl
+var nodeInterfaceFile []byte
+
+// Start transpiles TypeScript exercises, if needed, and starts the node process.
+func (n *nodeRunner) Start() (err error) {
+	absDir, err := filepath.Abs(n.dir)
+	if err != nil {
+		return err
+	}
+
+	workspace, err := newWorkspace()
+	if err != nil {
+		return fmt.Errorf("creating workspace: %w", err)
+	}
+
+	n.workspace = workspace
+
+	// runners that fail to start are not cleaned up
+	defer func() {
+		if err != nil {
+			_ = removeWorkspace(n.workspace)
+			n.workspace = ""
+		}
+	}()
+
+	modulePath := filepath.Join(absDir, "js")
+
+	if n.typescript {
+		modulePath = filepath.Join(n.workspace, nodeBuildDirname)
+
+		if err = n.transpile(modulePath); err != nil {
+			return err
+		}
+	}
+
+	// generate wrapper code from template
+	tpl := template.Must(template.New("").Parse(string(nodeInterfaceFile)))
+	b := new(bytes.Buffer)
+
+	if err = tpl.Execute(b, struct{ ModulePath string }{strconv.Quote(modulePath)}); err != nil {
+		return err
+	}
+
+	wrapperFilepath := filepath.Join(n.workspace, nodeWrapperFilename)
+	if err = os.WriteFile(wrapperFilepath, b.Bytes(), 0o600); err != nil {
+		return err
+	}
+
+	libDir, err := helperLibDir(absDir, settings.NodeLib)
+	if err != nil {
+		return err
+	}
+
+	n.cmd = exec.Command(nodeInstallation, wrapperFilepath)
+	n.cmd.Env = withEnv([]string{"NODE_PATH=" + libDir}, n.env) // so we can use shared helpers
+	n.cmd.Dir = n.dir
+
+	stdin, err := setupBuffers(n.cmd)
+	if err != nil {
+		return err
+	}
+
+	n.stdin = stdin
+
+	return startProcess(n.cmd, n.writable...)
+}
+
+// transpile compiles the TypeScript exercise into outDir.
+func (n *nodeRunner) transpile(outDir string) error {
+	command := n.transpileCommand(outDir)
+	transpiler, args := command[0], command[1:]
+
+	slog.LogAttrs(context.TODO(), slog.LevelDebug, "transpiling typescript",
+		slog.String("dir", n.dir),
+		slog.String("cmd", transpiler),
+		slog.Any("args", args),
+	)
+
+	outBuffer := new(bytes.Buffer)
+
+	//nolint:gosec // command is provided by the user
+	cmd := exec.Command(transpiler, args...)
+	cmd.Dir = n.dir
+	cmd.Env = withEnv(n.env)
+	cmd.Stdout = outBuffer // tsc reports errors on stdout
+	cmd.Stderr = outBuffer
+
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("transpile failed: %w: %s", err, outBuffer.String())
+	}
+
+	return nil
+}
+
+// transpileCommand returns the command that transpiles the exercise into outDir.
+//
+// The command is set by the Transpiler setting. The {outdir} and {entry} placeholders in it are
+// replaced by the output directory and the entry file; a command without them gets
+// '--outdir=<dir> <entry>' appended, which suits esbuild. Without a setting, tsc compiles the
+// exercise's tsconfig.json project if it has one, or else the entry file.
+func (n *nodeRunner) transpileCommand(outDir string) []string {
+	entry := filepath.FromSlash(typeScriptEntrypoint)
+
+	if settings.Transpiler == "" {
+		for _, name := range tsconfigFiles {
+			if _, err := os.Stat(filepath.Join(n.dir, name)); err == nil {
+				return []string{defaultTranspileCmd, "--project", filepath.FromSlash(name), "--outDir", outDir}
+			}
+		}
+
+		return []string{defaultTranspileCmd, "--outDir", outDir, entry}
+	}
+
+	command := strings.Fields(settings.Transpiler)
+
+	if !strings.Contains(settings.Transpiler, transpileOutDir) {
+		return append(command, "--outdir="+outDir, entry)
+	}
+
+	r := strings.NewReplacer(transpileOutDir, outDir, transpileEntry, entry)
+
+	for i, arg := range command {
+		command[i] = r.Replace(arg)
+	}
+
+	return command
+}
+
+func (n *nodeRunner) Stop() error {
+	return stopProcess(n.cmd, "node")
+}
+
+func (n *nodeRunner) Cleanup() error {
+	return removeWorkspace(n.workspace)
+}
+
+func (n *nodeRunner) Run(task *Task) (*Result, error) {
+	return runTaskRestarting(task, &n.cmd, &n.stdin, &n.restarts)
+}
+
+// String returns a string representation of the runner type.
+func (n *nodeRunner) String() string {
+	if n != nil && n.typescript {
+		return typeScriptRunnerName
+	}
+
+	return javaScriptRunnerName
+}
@@ -0,0 +1,195 @@
+package runners
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_newNodeRunners(t *testing.T) {
+	dir := filepath.Join("testdata", "2015", "01-testDayOne")
+
+	tests := []struct {
+		name   string
+		create RunnerCreator
+		want   Runner
+	}{
+		{
+			name:   "javascript",
+			create: newJavaScriptRunner,
+			want: &nodeRunner{
+				dir: dir,
+			},
+		},
+		{
+			name:   "typescript",
+			create: newTypeScriptRunner,
+			want: &nodeRunner{
+				dir:        dir,
+				typescript: true,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.create(dir))
+		})
+	}
+}
+
+func Test_nodeRunner_Cleanup(t *testing.T) {
+	workspace, err := newWorkspace()
+	require.NoError(t, err)
+	require.NoError(t, os.MkdirAll(filepath.Join(workspace, nodeBuildDirname), 0o750))
+
+	n := &nodeRunner{workspace: workspace, typescript: true}
+
+	require.NoError(t, n.Cleanup())
+	assert.NoDirExists(t, workspace)
+
+	assert.NoError(t, (&nodeRunner{}).Cleanup(), "runner that was not started")
+}
+
+func Test_nodeRunner_Stop(t *testing.T) {
+	n := &nodeRunner{}
+	assert.NoError(t, n.Stop())
+}
+
+func Test_nodeRunner_String(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "JavaScript", newJavaScriptRunner("").String())
+	assert.Equal(t, "TypeScript", newTypeScriptRunner("").String())
+
+	var n *nodeRunner
+	assert.Equal(t, "JavaScript", n.String(), "nil runner should return the name of the runner")
+}
+
+func Test_nodeRunner_transpileCommand(t *testing.T) {
+	outDir := filepath.Join("workspace", nodeBuildDirname)
+	entry := filepath.FromSlash(typeScriptEntrypoint)
+
+	tests := []struct {
+		name       string
+		transpiler string
+		tsconfig   string
+		want       []string
+	}{
+		{
+			name: "default",
+			want: []string{"tsc", "--outDir", outDir, entry},
+		},
+		{
+			name:     "default with project",
+			tsconfig: "ts/tsconfig.json",
+			want:     []string{"tsc", "--project", filepath.FromSlash("ts/tsconfig.json"), "--outDir", outDir},
+		},
+		{
+			name:     "default with project in exercise directory",
+			tsconfig: "tsconfig.json",
+			want:     []string{"tsc", "--project", "tsconfig.json", "--outDir", outDir},
+		},
+		{
+			name:       "without placeholders",
+			transpiler: "esbuild --bundle --platform=node",
+			want:       []string{"esbuild", "--bundle", "--platform=node", "--outdir=" + outDir, entry},
+		},
+		{
+			name:       "with placeholders",
+			transpiler: "tsc --strict --outDir {outdir} {entry}",
+			want:       []string{"tsc", "--strict", "--outDir", outDir, entry},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+
+			if tt.tsconfig != "" {
+				require.NoError(t, os.MkdirAll(filepath.Join(dir, "ts"), 0o750))
+				require.NoError(t, os.WriteFile(filepath.Join(dir, tt.tsconfig), []byte("{}"), 0o600))
+			}
+
+			Configure(Settings{Transpiler: tt.transpiler})
+			t.Cleanup(func() { Configure(Settings{}) })
+
+			n := &nodeRunner{dir: dir, typescript: true}
+			assert.Equal(t, tt.want, n.transpileCommand(outDir))
+		})
+	}
+}
+
+func Test_nodeRunner_transpile(t *testing.T) {
+	script := filepath.Join(t.TempDir(), "fake-esbuild.sh")
+	require.NoError(t, os.WriteFile(script, []byte(`d="${1#--outdir=}" && mkdir -p "$d" && echo "$2" > "$d/entry"`), 0o600))
+
+	Configure(Settings{Transpiler: "sh " + script})
+	t.Cleanup(func() { Configure(Settings{}) })
+
+	outDir := filepath.Join(t.TempDir(), nodeBuildDirname)
+
+	n := &nodeRunner{dir: t.TempDir(), typescript: true}
+	require.NoError(t, n.transpile(outDir))
+
+	got, err := os.ReadFile(filepath.Join(outDir, "entry"))
+	require.NoError(t, err)
+	assert.Equal(t, filepath.FromSlash(typeScriptEntrypoint)+"\n", string(got))
+}
+
+// Test_nodeRunner_transpile_documented runs the transpiler commands given as examples in the
+// documentation, where they are installed, and loads the output with node.
+func Test_nodeRunner_transpile_documented(t *testing.T) {
+	tests := []struct {
+		name       string
+		transpiler string
+		tsconfig   bool
+	}{
+		{name: "tsc", transpiler: ""},
+		{name: "tsc with project", transpiler: "", tsconfig: true},
+		{name: "tsc with placeholders", transpiler: "tsc --strict --outDir {outdir} {entry}"},
+		{name: "esbuild", transpiler: "esbuild --bundle --platform=node"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			program := defaultTranspileCmd
+			if tt.transpiler != "" {
+				program = strings.Fields(tt.transpiler)[0]
+			}
+
+			for _, p := range []string{program, nodeInstallation} {
+				if _, err := exec.LookPath(p); err != nil {
+					t.Skipf("%s not installed", p)
+				}
+			}
+
+			dir := t.TempDir()
+			require.NoError(t, os.MkdirAll(filepath.Join(dir, "ts"), 0o750))
+			require.NoError(t, os.WriteFile(filepath.Join(dir, filepath.FromSlash(typeScriptEntrypoint)),
+				[]byte("export function one(input: string): unknown {\n  return input.length;\n}\n"), 0o600))
+
+			if tt.tsconfig {
+				require.NoError(t, os.WriteFile(filepath.Join(dir, "ts", "tsconfig.json"),
+					[]byte(`{"compilerOptions": {"module": "commonjs", "target": "es2020", "strict": true}, "include": ["*.ts"]}`), 0o600))
+			}
+
+			Configure(Settings{Transpiler: tt.transpiler})
+			t.Cleanup(func() { Configure(Settings{}) })
+
+			outDir := filepath.Join(t.TempDir(), nodeBuildDirname)
+
+			n := &nodeRunner{dir: dir, typescript: true}
+			require.NoError(t, n.transpile(outDir))
+
+			out, err := exec.Command(nodeInstallation, "-e", "process.stdout.write(String(require(process.argv[1]).one('abc')))", outDir).Output()
+			require.NoError(t, err)
+			assert.Equal(t, "3", string(out))
+		})
+	}
+}
@@ -0,0 +1,238 @@
+package runners
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"slices"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// process tracks a runner process from the time its output is set up until it is released.
+type process struct {
+	stdout io.Reader
+
+	// messages delivers the lines written to stdout. It is closed when stdout is closed,
+	// usually because the process exited.
+	messages chan message
+
+	// released is closed once nothing reads messages anymore, so that remaining output is
+	// discarded instead of blocking the process.
+	released    chan struct{}
+	releaseOnce sync.Once
+
+	// done is closed once the process has exited and its output has been copied.
+	done chan struct{}
+
+	// inputID is the ID of the input last registered with the process.
+	inputID string
+
+	// writable lists directories the process may write to in addition to those of the
+	// sandbox, kept for restarts.
+	writable []string
+}
+
+var (
+	processMux sync.Mutex
+	processes  = map[*exec.Cmd]*process{}
+)
+
+// trackProcess starts tracking a runner process whose stdout is read from stdout.
+func trackProcess(cmd *exec.Cmd, stdout io.Reader) {
+	p := &process{
+		stdout:   stdout,
+		messages: make(chan message, pendingMessages),
+		released: make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+
+	processMux.Lock()
+	processes[cmd] = p
+	processMux.Unlock()
+}
+
+func trackedProcess(cmd *exec.Cmd) (*process, bool) {
+	processMux.Lock()
+	defer processMux.Unlock()
+
+	p, ok := processes[cmd]
+
+	return p, ok
+}
+
+// startProcess starts a runner process set up by setupBuffers, in the sandbox if one is
+// configured. A sandboxed process may also write to the writable directories. Processes that
+// have not been released are killed if elf is interrupted.
+func startProcess(cmd *exec.Cmd, writable ...string) error {
+	p, ok := trackedProcess(cmd)
+	if !ok {
+		return errors.New("runner process output not set up")
+	}
+
+	p.writable = writable
+
+	start := cmd.Start
+	if settings.Sandbox.Enabled {
+		sb := settings.Sandbox
+		sb.Writable = append(slices.Clip(sb.Writable), writable...)
+
+		start = func() error { return startSandboxed(cmd, sb) }
+	}
+
+	if err := start(); err != nil {
+		releaseProcess(cmd)
+		return err
+	}
+
+	interruptOnce.Do(removeWorkspacesOnInterrupt)
+
+	go p.read(cmd)
+
+	return nil
+}
+
+// read delivers the output of a started process until it closes stdout, then waits for the
+// process to exit.
+func (p *process) read(cmd *exec.Cmd) {
+	scanner := bufio.NewScanner(p.stdout)
+	scanner.Buffer(make([]byte, 0, bufio.MaxScanTokenSize), maxMessageSize)
+
+	for scanner.Scan() {
+		// the scanner reuses its buffer
+		p.send(decodeMessage(append([]byte(nil), scanner.Bytes()...)))
+	}
+
+	if err := scanner.Err(); err != nil {
+		p.send(message{err: fmt.Errorf("reading runner output: %w", err)})
+
+		// the process may still be writing; it must not block on a full pipe
+		_, _ = io.Copy(io.Discard, p.stdout)
+	}
+
+	close(p.messages)
+
+	_ = cmd.Wait() // the exit status is read from cmd.ProcessState
+	close(p.done)
+}
+
+func (p *process) send(msg message) {
+	select {
+	case p.messages <- msg:
+	case <-p.released:
+	}
+}
+
+func (p *process) release() {
+	p.releaseOnce.Do(func() { close(p.released) })
+}
+
+// exited reports whether a started process has exited. Once it has, cmd.ProcessState and
+// the captured output may be read.
+func exited(cmd *exec.Cmd) bool {
+	p, ok := trackedProcess(cmd)
+	if !ok {
+		return cmd.ProcessState != nil
+	}
+
+	select {
+	case <-p.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// restartProcess starts a runner process again with the same command and environment, and
+// stops tracking the process it replaces.
+func restartProcess(cmd *exec.Cmd) (*exec.Cmd, io.WriteCloser, error) {
+	var writable []string
+	if p, ok := trackedProcess(cmd); ok {
+		writable = p.writable
+	}
+
+	releaseProcess(cmd)
+
+	next := exec.Command(cmd.Path, cmd.Args[1:]...) //nolint:gosec // same command as before
+	next.Dir = cmd.Dir
+	next.Env = cmd.Env
+
+	stdin, err := setupBuffers(next)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err = startProcess(next, writable...); err != nil {
+		return nil, nil, err
+	}
+
+	return next, stdin, nil
+}
+
+// releaseProcess stops tracking a runner process once its output is no longer read.
+func releaseProcess(cmd *exec.Cmd) {
+	processMux.Lock()
+	p, ok := processes[cmd]
+	delete(processes, cmd)
+	processMux.Unlock()
+
+	if ok {
+		p.release()
+	}
+}
+
+// killProcesses kills every runner process that has not been released.
+func killProcesses() {
+	processMux.Lock()
+	defer processMux.Unlock()
+
+	for cmd := range processes {
+		if cmd.Process != nil {
+			_ = cmd.Process.Kill()
+		}
+	}
+}
+
+// stopProcess asks a runner process to exit, killing it if it has not exited after a few
+// seconds, and stops tracking it.
+func stopProcess(cmd *exec.Cmd, name string) error {
+	const processExitTimeout time.Duration = 5 * time.Second
+
+	if cmd == nil || cmd.Process == nil {
+		return nil
+	}
+
+	p, ok := trackedProcess(cmd)
+	if !ok {
+		return nil
+	}
+
+	// output written while exiting is discarded
+	releaseProcess(cmd)
+
+	// First try to send a SIGTERM, unless the process is already gone.
+	select {
+	case <-p.done:
+		return nil
+	default:
+	}
+
+	if err := cmd.Process.Signal(syscall.SIGTERM); err != nil && !errors.Is(err, os.ErrProcessDone) {
+		return fmt.Errorf("failed to send SIGTERM to %s process: %w", name, err)
+	}
+
+	// wait up to 5 seconds for the process to exit.
+	select {
+	case <-time.After(processExitTimeout):
+		if err := cmd.Process.Kill(); err != nil {
+			return fmt.Errorf("failed to kill %s process: %w", name, err)
+		}
+	case <-p.done:
+	}
+
+	return nil
+}
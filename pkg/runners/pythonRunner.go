@@ -1,45 +1,127 @@
 package runners
 
 import (
+	"bytes"
+	"context"
 	_ "embed"
-	"encoding/json"
-	"errors"
 	"fmt"
 	"io"
+	"log/slog"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"runtime"
 	"strings"
-	"syscall"
-	"time"
 )
 
 const (
 	pythonRunnerName      string = "Python"
 	python3Installation   string = "python3"
 	pythonWrapperFilename string = "runtime-wrapper.py"
+	pythonHelperFilename  string = "elf.py"
+	pythonVenvDirname     string = ".venv"
+	pythonVersionFilename string = ".python-version"
 )
 
+// pythonVersionScript prints the implementation and version of the interpreter, e.g.
+// "CPython 3.12.1" or "PyPy 3.10.14".
+const pythonVersionScript = "import platform; print(platform.python_implementation(), platform.python_version())"
+
 type pythonRunner struct {
-	cmd             *exec.Cmd
-	dir             string
-	stdin           io.WriteCloser
-	wrapperFilepath string
+	cmd       *exec.Cmd
+	dir       string
+	stdin     io.WriteCloser
+	restarts  int
+	workspace string
+
+	// implementation and version identify the interpreter, e.g. "PyPy" and "3.10.14", set
+	// when started.
+	implementation string
+	version        string
+
+	// env and interpreter are set by variants
+	env         []string
+	interpreter string
+
+	// writable lists extra directories its processes may write to when sandboxed
+	writable []string
 }
 
 func newPythonRunner(dir string) Runner {
 	return &pythonRunner{
-		dir:             dir,
-		wrapperFilepath: filepath.Join(dir, pythonWrapperFilename),
+		dir: dir,
 	}
 }
 
+func (p *pythonRunner) applyVariant(v Variant) error {
+	if err := v.unsupported(pythonRunnerName, false, true); err != nil {
+		return err
+	}
+
+	p.env = v.Env
+	p.interpreter = v.Interpreter
+
+	return nil
+}
+
+func (p *pythonRunner) allowWrite(dir string) {
+	p.writable = append(p.writable, dir)
+}
+
 //go:embed interface/python.templ
 var pythonInterface []byte
 
-func (p *pythonRunner) Start() error {
+// pythonHelper is the module exercises import helpers like progress reporting from. It is
+// found next to the wrapper.
+//
+//go:embed interface/elf.py
+var pythonHelper []byte
+
+// Start writes the wrapper and starts it with the interpreter selected for the exercise.
+func (p *pythonRunner) Start() (err error) {
+	interpreter, err := pythonInterpreter(p.dir)
+	if p.interpreter != "" {
+		interpreter, err = configuredPython(p.interpreter)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	p.implementation, p.version, err = pythonVersion(interpreter)
+	if err != nil {
+		return err
+	}
+
+	slog.LogAttrs(context.TODO(), slog.LevelDebug, "selected python interpreter",
+		slog.String("dir", p.dir),
+		slog.String("interpreter", interpreter),
+		slog.String("implementation", p.implementation),
+		slog.String("version", p.version),
+	)
+
+	workspace, err := newWorkspace()
+	if err != nil {
+		return fmt.Errorf("creating workspace: %w", err)
+	}
+
+	p.workspace = workspace
+
+	// runners that fail to start are not cleaned up
+	defer func() {
+		if err != nil {
+			_ = removeWorkspace(p.workspace)
+			p.workspace = ""
+		}
+	}()
+
 	// Save interaction code
-	if err := os.WriteFile(p.wrapperFilepath, pythonInterface, 0o600); err != nil {
+	wrapperFilepath := filepath.Join(p.workspace, pythonWrapperFilename)
+	if err = os.WriteFile(wrapperFilepath, pythonInterface, 0o600); err != nil {
+		return err
+	}
+
+	if err = os.WriteFile(filepath.Join(p.workspace, pythonHelperFilename), pythonHelper, 0o600); err != nil {
 		return err
 	}
 
@@ -49,13 +131,19 @@ func (p *pythonRunner) Start() error {
 		return err
 	}
 
+	libDir, err := helperLibDir(absDir, settings.PythonLib)
+	if err != nil {
+		return err
+	}
+
 	pythonPathVar := strings.Join([]string{
-		filepath.Join(absDir, "../../..", "lib"), // so we can use aocpy
-		filepath.Join(absDir, "py"),              // so we can import stuff in the exercises directory
-	}, ":")
+		libDir,                      // so we can use helper modules like aocpy
+		filepath.Join(absDir, "py"), // so we can import stuff in the exercises directory
+		absDir,                      // so the wrapper can import the exercise
+	}, string(filepath.ListSeparator))
 
-	p.cmd = exec.Command(python3Installation, "-B", pythonWrapperFilename) // -B prevents .pyc files from being written
-	p.cmd.Env = append(p.cmd.Env, "PYTHONPATH="+pythonPathVar)
+	p.cmd = exec.Command(interpreter, "-B", wrapperFilepath) // -B prevents .pyc files from being written
+	p.cmd.Env = withEnv([]string{"PYTHONPATH=" + pythonPathVar}, p.env)
 	p.cmd.Dir = p.dir
 
 	stdin, err := setupBuffers(p.cmd)
@@ -65,73 +153,147 @@ func (p *pythonRunner) Start() error {
 
 	p.stdin = stdin
 
-	return p.cmd.Start()
+	return startProcess(p.cmd, p.writable...)
 }
 
 func (p *pythonRunner) Stop() error {
-	const processExitTimeout time.Duration = 5 * time.Second
+	return stopProcess(p.cmd, "python")
+}
 
-	if p.cmd == nil || p.cmd.Process == nil {
-		return nil
+func (p *pythonRunner) Cleanup() error {
+	return removeWorkspace(p.workspace)
+}
+
+func (p *pythonRunner) Run(task *Task) (*Result, error) {
+	return runTaskRestarting(task, &p.cmd, &p.stdin, &p.restarts)
+}
+
+// String names the interpreter implementation once started, e.g. "Python (PyPy)", so
+// benchmarks keep results from different interpreters apart. Before then it returns the name
+// of the runner.
+func (p *pythonRunner) String() string {
+	if p == nil || p.implementation == "" {
+		return pythonRunnerName
 	}
 
-	// First try to send a SIGTERM.
-	if err := p.cmd.Process.Signal(syscall.SIGTERM); err != nil {
-		return fmt.Errorf("failed to send SIGTERM to python process: %w", err)
+	return pythonRunnerName + " (" + p.implementation + ")"
+}
+
+// Version returns the version of the interpreter, e.g. "3.12.1", once started.
+func (p *pythonRunner) Version() string {
+	if p == nil {
+		return ""
 	}
 
-	// Wait for the process to exit, but not forever.
-	done := make(chan error, 1)
-	go func() {
-		_, err := p.cmd.Process.Wait()
-		done <- err
-	}()
+	return p.version
+}
 
-	// wait up to 5 seconds for the process to exit.
-	select {
-	case <-time.After(processExitTimeout):
-		if err := p.cmd.Process.Kill(); err != nil {
-			return fmt.Errorf("failed to kill python process: %w", err)
+// pythonInterpreter returns the interpreter for the exercise in dir. The first of these is
+// used:
+//
+//   - a .venv or .python-version (other than "system") in the exercise directory
+//   - the configured interpreter
+//   - the nearest .venv above the exercise directory
+//   - python3
+func pythonInterpreter(dir string) (string, error) {
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+
+	if python, ok := venvPython(filepath.Join(absDir, pythonVenvDirname)); ok {
+		return python, nil
+	}
+
+	data, err := os.ReadFile(filepath.Join(absDir, pythonVersionFilename))
+	if err == nil {
+		version, _, _ := strings.Cut(strings.TrimSpace(string(data)), "\n")
+		if command := pythonVersionCommand(strings.TrimSpace(version)); command != "" {
+			return command, nil
 		}
-	case err := <-done:
-		if err != nil {
-			return fmt.Errorf("failed to stop python process: %w", err)
+	}
+
+	if settings.Python != "" {
+		return configuredPython(settings.Python)
+	}
+
+	for d := filepath.Dir(absDir); ; d = filepath.Dir(d) {
+		if python, ok := venvPython(filepath.Join(d, pythonVenvDirname)); ok {
+			return python, nil
+		}
+
+		if filepath.Dir(d) == d {
+			break
 		}
 	}
 
-	return nil
+	return python3Installation, nil
 }
 
-func (p *pythonRunner) Cleanup() error {
-	err := os.Remove(p.wrapperFilepath)
+// configuredPython returns the interpreter for a configured command, interpreter path, or
+// virtual environment.
+func configuredPython(python string) (string, error) {
+	info, err := os.Stat(python)
+	if err != nil || !info.IsDir() {
+		return python, nil
+	}
 
-	if errors.Is(err, os.ErrNotExist) {
-		// already gone, maybe log this?
-		return nil
+	venv, ok := venvPython(python)
+	if !ok {
+		return "", fmt.Errorf("no python interpreter in virtual environment %s", python)
 	}
 
-	return err
+	return venv, nil
 }
 
-func (p *pythonRunner) Run(task *Task) (*Result, error) {
-	taskJSON, err := json.Marshal(task)
-	if err != nil {
-		return nil, fmt.Errorf("marshalling task to json: %w", err)
+// venvPython returns the interpreter of the virtual environment at venv, if there is one.
+func venvPython(venv string) (string, bool) {
+	python := filepath.Join(venv, "bin", "python")
+	if runtime.GOOS == "windows" {
+		python = filepath.Join(venv, "Scripts", "python.exe")
 	}
 
-	_, err = p.stdin.Write(append(taskJSON, '\n'))
-	if err != nil {
-		return nil, fmt.Errorf("writing task to stdin: %w", err)
+	info, err := os.Stat(python)
+	if err != nil || info.IsDir() {
+		return "", false
+	}
+
+	return python, true
+}
+
+// pythonVersionCommand returns the interpreter command for a version in a .python-version
+// file. Versions naming an interpreter (e.g. "pypy3.10") are used as is, and bare version
+// numbers (e.g. "3.12") select the matching pythonX.Y. It returns an empty string for "system"
+// or no version, which leave the choice to the other rules.
+func pythonVersionCommand(version string) string {
+	if version == "" || version == "system" {
+		return ""
 	}
 
-	r := new(Result)
-	if jsonErr := readJSONFromCommand(r, p.cmd); jsonErr != nil {
-		return nil, jsonErr
+	if version[0] >= '0' && version[0] <= '9' {
+		major, minor, _ := strings.Cut(version, ".")
+		minor, _, _ = strings.Cut(minor, ".")
+
+		return strings.TrimSuffix("python"+major+"."+minor, ".")
 	}
 
-	return r, nil
+	return version
 }
 
-func (p *pythonRunner) String() string {
-	return pythonRunnerName
+// pythonVersion returns the implementation and version of an interpreter.
+func pythonVersion(interpreter string) (string, string, error) {
+	errBuf := new(bytes.Buffer)
+
+	//nolint:gosec // interpreter is provided by the user
+	cmd := exec.Command(interpreter, "-c", pythonVersionScript)
+	cmd.Stderr = errBuf
+
+	out, err := cmd.Output()
+	if err != nil {
+		return "", "", fmt.Errorf("python interpreter %s: %w: %s", interpreter, err, strings.TrimSpace(errBuf.String()))
+	}
+
+	implementation, version, _ := strings.Cut(strings.TrimSpace(string(out)), " ")
+
+	return implementation, version, nil
 }
@@ -2,7 +2,9 @@ package runners
 
 import (
 	"os"
+	"os/exec"
 	"path/filepath"
+	"runtime"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -25,10 +27,9 @@ func Test_newPythonRunner(t *testing.T) {
 				dir: filepath.Join("testdata", "2015", "01-testDayOne", "py"),
 			},
 			want: &pythonRunner{
-				dir:             filepath.Join("testdata", "2015", "01-testDayOne", "py"),
-				cmd:             nil,
-				wrapperFilepath: filepath.Join("testdata", "2015", "01-testDayOne", "py", "runtime-wrapper.py"),
-				stdin:           nil,
+				dir:   filepath.Join("testdata", "2015", "01-testDayOne", "py"),
+				cmd:   nil,
+				stdin: nil,
 			},
 		},
 	}
@@ -41,55 +42,34 @@ func Test_newPythonRunner(t *testing.T) {
 }
 
 func Test_pythonRunner_Cleanup(t *testing.T) {
-	tf, err := os.MkdirTemp("", "test-py")
+	workspace, err := newWorkspace()
 	require.NoError(t, err)
-
-	defer require.NoError(t, os.RemoveAll(tf))
-
-	exDir := filepath.Join(tf, "2015", "01-testDayOne", "py")
-	require.NoError(t, os.MkdirAll(exDir, 0o750))
+	require.NoError(t, os.WriteFile(filepath.Join(workspace, pythonWrapperFilename), []byte("fake test data"), 0o600))
 
 	tests := []struct {
-		name             string
-		p                *pythonRunner
-		writeWrapperFile bool
-		assertion        assert.ErrorAssertionFunc
-		err              error
+		name      string
+		p         *pythonRunner
+		assertion assert.ErrorAssertionFunc
 	}{
 		{
-			name: "existing wrapper file",
-			p: &pythonRunner{
-				dir:             exDir,
-				cmd:             nil,
-				wrapperFilepath: filepath.Join(exDir, pythonWrapperFilename),
-				stdin:           nil,
-			},
-			writeWrapperFile: true,
-			assertion:        assert.NoError,
+			name:      "existing workspace",
+			p:         &pythonRunner{workspace: workspace},
+			assertion: assert.NoError,
 		},
 		{
-			name: "no file",
-			p: &pythonRunner{
-				dir:             exDir,
-				cmd:             nil,
-				wrapperFilepath: filepath.Join(exDir, pythonWrapperFilename),
-				stdin:           nil,
-			},
-			writeWrapperFile: false,
-			assertion:        assert.NoError,
+			name:      "not started",
+			p:         &pythonRunner{},
+			assertion: assert.NoError,
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if tt.writeWrapperFile {
-				require.NoError(t, os.WriteFile(tt.p.wrapperFilepath, []byte("fake test data"), 0o600))
-			}
-			require.DirExists(t, tt.p.dir)
+			tt.assertion(t, tt.p.Cleanup())
 
-			tErr := tt.p.Cleanup()
-
-			tt.assertion(t, tErr)
+			if tt.p.workspace != "" {
+				assert.NoDirExists(t, tt.p.workspace)
+			}
 		})
 	}
 }
@@ -119,7 +99,154 @@ func Test_pythonRunner_String(t *testing.T) {
 
 	p := &pythonRunner{}
 	assert.Equal(t, "Python", p.String())
+	assert.Empty(t, p.Version())
+
+	p = &pythonRunner{implementation: "PyPy", version: "3.10.14"}
+	assert.Equal(t, "Python (PyPy)", p.String(), "started runner should name the interpreter")
+	assert.Equal(t, "3.10.14", p.Version(), "started runner should know the interpreter version")
 
 	p = nil
 	assert.Equal(t, "Python", p.String(), "nil runner should return the name of the runner")
+	assert.Empty(t, p.Version())
+}
+
+// makeVenv creates a virtual environment with a placeholder interpreter and returns the path
+// to the interpreter.
+func makeVenv(t *testing.T, dir string) string {
+	t.Helper()
+
+	python := filepath.Join(dir, pythonVenvDirname, "bin", "python")
+	if runtime.GOOS == "windows" {
+		python = filepath.Join(dir, pythonVenvDirname, "Scripts", "python.exe")
+	}
+
+	require.NoError(t, os.MkdirAll(filepath.Dir(python), 0o750))
+	require.NoError(t, os.WriteFile(python, nil, 0o600))
+
+	return python
+}
+
+func Test_pythonInterpreter(t *testing.T) {
+	tests := []struct {
+		name      string
+		setup     func(t *testing.T, repo, exercise string) (configured, want string)
+		assertion assert.ErrorAssertionFunc
+	}{
+		{
+			name: "default",
+			setup: func(*testing.T, string, string) (string, string) {
+				return "", python3Installation
+			},
+			assertion: assert.NoError,
+		},
+		{
+			name: "exercise venv",
+			setup: func(t *testing.T, repo, exercise string) (string, string) {
+				makeVenv(t, repo)
+				return "pypy3", makeVenv(t, exercise)
+			},
+			assertion: assert.NoError,
+		},
+		{
+			name: "exercise python version",
+			setup: func(t *testing.T, _, exercise string) (string, string) {
+				require.NoError(t, os.WriteFile(filepath.Join(exercise, pythonVersionFilename), []byte("3.12.1\n"), 0o600))
+				return "pypy3", "python3.12"
+			},
+			assertion: assert.NoError,
+		},
+		{
+			name: "system python version",
+			setup: func(t *testing.T, _, exercise string) (string, string) {
+				require.NoError(t, os.WriteFile(filepath.Join(exercise, pythonVersionFilename), []byte("system\n"), 0o600))
+				return "pypy3", "pypy3"
+			},
+			assertion: assert.NoError,
+		},
+		{
+			name: "configured command",
+			setup: func(t *testing.T, repo, _ string) (string, string) {
+				makeVenv(t, repo)
+				return "pypy3", "pypy3"
+			},
+			assertion: assert.NoError,
+		},
+		{
+			name: "configured venv",
+			setup: func(t *testing.T, _, _ string) (string, string) {
+				venv := t.TempDir()
+				python := makeVenv(t, venv)
+
+				return filepath.Join(venv, pythonVenvDirname), python
+			},
+			assertion: assert.NoError,
+		},
+		{
+			name: "configured directory without interpreter",
+			setup: func(t *testing.T, _, _ string) (string, string) {
+				return t.TempDir(), ""
+			},
+			assertion: assert.Error,
+		},
+		{
+			name: "repo venv",
+			setup: func(t *testing.T, repo, _ string) (string, string) {
+				return "", makeVenv(t, repo)
+			},
+			assertion: assert.NoError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := t.TempDir()
+			exercise := filepath.Join(repo, "exercises", "2015", "01-notQuiteLisp")
+			require.NoError(t, os.MkdirAll(exercise, 0o750))
+
+			configured, want := tt.setup(t, repo, exercise)
+
+			Configure(Settings{Python: configured})
+			t.Cleanup(func() { Configure(Settings{}) })
+
+			got, err := pythonInterpreter(exercise)
+
+			tt.assertion(t, err)
+			assert.Equal(t, want, got)
+		})
+	}
+}
+
+func Test_pythonVersionCommand(t *testing.T) {
+	tests := []struct {
+		version string
+		want    string
+	}{
+		{"3", "python3"},
+		{"3.12", "python3.12"},
+		{"3.12.1", "python3.12"},
+		{"pypy3.10", "pypy3.10"},
+		{"pypy3.10-7.3.15", "pypy3.10-7.3.15"},
+		{"system", ""},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.version, func(t *testing.T) {
+			assert.Equal(t, tt.want, pythonVersionCommand(tt.version))
+		})
+	}
+}
+
+func Test_pythonVersion(t *testing.T) {
+	if _, err := exec.LookPath(python3Installation); err != nil {
+		t.Skip("python3 not installed")
+	}
+
+	implementation, version, err := pythonVersion(python3Installation)
+	require.NoError(t, err)
+	assert.Regexp(t, `^\w+$`, implementation)
+	assert.Regexp(t, `^\d+\.\d+`, version)
+
+	_, _, err = pythonVersion(filepath.Join(t.TempDir(), "no-such-python"))
+	assert.Error(t, err)
 }
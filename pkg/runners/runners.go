@@ -1,5 +1,7 @@
 package runners
 
+import "strings"
+
 // Part represents a section or segment of a task or process.
 type Part uint8
 
@@ -12,7 +14,8 @@ const (
 // Runner is an interface defining methods for starting, stopping,
 // cleaning up, and running tasks.
 type Runner interface {
-	// Start initializes the runner.
+	// Start initializes the runner. A runner that fails to start removes anything it created,
+	// so Stop and Cleanup are only needed after a successful start.
 	Start() error
 	// Stop terminates the runner.
 	Stop() error
@@ -24,6 +27,23 @@ type Runner interface {
 	String() string
 }
 
+// Versioned is implemented by runners that know the version of their interpreter or toolchain
+// once started. The version is kept apart from the name of the runner, so results stay under
+// the same implementation when the version changes.
+type Versioned interface {
+	Version() string
+}
+
+// VersionOf returns the version of a started runner, or an empty string if it doesn't report
+// one.
+func VersionOf(r Runner) string {
+	if v, ok := r.(Versioned); ok {
+		return v.Version()
+	}
+
+	return ""
+}
+
 // ResultOrError holds either the result of a task or an error.
 // It is useful for communicating results and errors from asynchronous operations.
 type ResultOrError struct {
@@ -40,6 +60,43 @@ type RunnerCreator func(dir string) Runner
 // RunnerCreator functions. This allows for the dynamic creation of runners
 // based on the runner type.
 var Available = map[string]RunnerCreator{
-	"go": newGolangRunner,
-	"py": newPythonRunner,
+	"go":   newGolangRunner,
+	"py":   newPythonRunner,
+	"js":   newJavaScriptRunner,
+	"ts":   newTypeScriptRunner,
+	"c":    newCRunner,
+	"cpp":  newCppRunner,
+	"wasm": newWasmRunner,
+}
+
+// builtinNames are the names the built-in runners are shown with, by runner type.
+var builtinNames = map[string]string{
+	"go":   goRunnerName,
+	"py":   pythonRunnerName,
+	"js":   javaScriptRunnerName,
+	"ts":   typeScriptRunnerName,
+	"c":    cLanguage.name,
+	"cpp":  cppLanguage.name,
+	"wasm": wasmRunnerName,
+}
+
+// NameOf returns the name a runner is shown with, by runner type or variant key, without
+// creating it. Python runners are named after their interpreter once started; this returns
+// the name they have before.
+func NameOf(lang string) (string, bool) {
+	lang = strings.ToLower(lang)
+
+	if name, ok := builtinNames[lang]; ok {
+		return name, true
+	}
+
+	if def, ok := Lookup(lang); ok {
+		return def.Name, true
+	}
+
+	if v, ok := LookupVariant(lang); ok {
+		return v.Name, true
+	}
+
+	return "", false
 }
@@ -0,0 +1,89 @@
+package runners
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// LimitCPUTime is reported in results when a sandboxed task is stopped by the CPU time limit.
+const LimitCPUTime = "cpu-time"
+
+// Sandbox restricts the processes that run exercises. It is only enforced on Linux; other
+// platforms run exercises without restrictions. Builds are never sandboxed.
+//
+// Each restriction is applied when the system supports it, so a sandbox may be weaker than
+// configured; unavailable restrictions are logged once.
+type Sandbox struct {
+	// Enabled turns on the sandbox for every runner except WebAssembly, which is always
+	// isolated and only subject to the CPU time limit.
+	Enabled bool
+
+	// CPUTime limits the CPU time used by a runner process across all of its tasks. A
+	// process serves every part and benchmark iteration of a run, so the limit must cover
+	// them all; a process restarted after being stopped gets a new allowance. Zero means no
+	// limit.
+	CPUTime time.Duration
+
+	// Memory limits the address space of a runner process, in bytes. Runtimes that reserve
+	// large address ranges up front, like Node, need a generous limit. Allocations beyond it
+	// fail, which usually crashes the process. Zero means no limit.
+	Memory uint64
+
+	// OpenFiles limits the number of files a runner process may have open. Opening more
+	// fails. Zero means no limit.
+	OpenFiles uint64
+
+	// Network allows network access. Without it, runner processes get a private network
+	// namespace with no interfaces.
+	Network bool
+
+	// Writable lists the directories runner processes may write to. Everything else is
+	// read-only.
+	Writable []string
+}
+
+// writeAllower is implemented by runners whose processes are sandboxed.
+type writeAllower interface {
+	allowWrite(dir string)
+}
+
+// AllowWrite lets the processes of a runner write to dir when sandboxed, e.g. the output
+// directory of a visualization. It only affects that runner, and must be called before the
+// runner is started.
+func AllowWrite(r Runner, dir string) {
+	if w, ok := r.(writeAllower); ok {
+		w.allowWrite(dir)
+	}
+}
+
+// LimitError reports a runner process stopped for exceeding a sandbox limit.
+type LimitError struct {
+	Limit   string
+	Sandbox Sandbox
+}
+
+func (e *LimitError) Error() string {
+	switch e.Limit {
+	case LimitCPUTime:
+		return fmt.Sprintf("cpu time limit of %s exceeded", e.Sandbox.CPUTime)
+	default:
+		return e.Limit + " limit exceeded"
+	}
+}
+
+// failureLimits describes the limits that make system calls fail rather than stopping a
+// process, so a crash can be traced back to them. It is empty if none are set.
+func (sb Sandbox) failureLimits() string {
+	var limits []string
+
+	if sb.Memory > 0 {
+		limits = append(limits, fmt.Sprintf("memory limit of %d MiB", sb.Memory>>20))
+	}
+
+	if sb.OpenFiles > 0 {
+		limits = append(limits, fmt.Sprintf("open file limit of %d", sb.OpenFiles))
+	}
+
+	return strings.Join(limits, ", ")
+}
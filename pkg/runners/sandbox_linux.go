@@ -0,0 +1,291 @@
+//go:build linux
+
+package runners
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"sync"
+	"syscall"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+var (
+	namespaceOnce      sync.Once
+	namespaceAvailable bool
+	landlockWarnOnce   sync.Once
+)
+
+// landlockWriteAccess are the filesystem rights denied outside writable directories, by
+// Landlock ABI version. Reading and executing are always allowed.
+var landlockWriteAccess = []uint64{
+	1: unix.LANDLOCK_ACCESS_FS_WRITE_FILE |
+		unix.LANDLOCK_ACCESS_FS_REMOVE_DIR |
+		unix.LANDLOCK_ACCESS_FS_REMOVE_FILE |
+		unix.LANDLOCK_ACCESS_FS_MAKE_CHAR |
+		unix.LANDLOCK_ACCESS_FS_MAKE_DIR |
+		unix.LANDLOCK_ACCESS_FS_MAKE_REG |
+		unix.LANDLOCK_ACCESS_FS_MAKE_SOCK |
+		unix.LANDLOCK_ACCESS_FS_MAKE_FIFO |
+		unix.LANDLOCK_ACCESS_FS_MAKE_BLOCK |
+		unix.LANDLOCK_ACCESS_FS_MAKE_SYM,
+	2: unix.LANDLOCK_ACCESS_FS_REFER,
+	3: unix.LANDLOCK_ACCESS_FS_TRUNCATE,
+}
+
+// Environment variables that make elf act as the sandbox shim, which applies resource limits
+// to itself before executing the runner process. Limits are per process, so they can't be
+// set for a child from os/exec before it starts.
+const (
+	sandboxExecEnv   = "ELF_SANDBOX_EXEC"
+	sandboxLimitsEnv = "ELF_SANDBOX_LIMITS"
+)
+
+// selfExecutable is elf itself, even if its file was replaced since it started.
+const selfExecutable = "/proc/self/exe"
+
+// ExecSandboxShim turns the process into the sandbox shim if it was started as one by a
+// sandboxed runner, and returns otherwise. Programs that run sandboxed runners call it first
+// thing in main, since the shim is started from the same executable.
+func ExecSandboxShim() {
+	if path, ok := os.LookupEnv(sandboxExecEnv); ok {
+		execWithLimits(path, os.Getenv(sandboxLimitsEnv))
+	}
+}
+
+// startSandboxed starts a runner process with the sandbox restrictions applied.
+func startSandboxed(cmd *exec.Cmd, sb Sandbox) error {
+	if !sb.Network && namespacesAvailable() {
+		if cmd.SysProcAttr == nil {
+			cmd.SysProcAttr = &syscall.SysProcAttr{}
+		}
+
+		// no uid mappings are written: the parent thread cannot write to /proc once restricted
+		cmd.SysProcAttr.Cloneflags |= syscall.CLONE_NEWUSER | syscall.CLONE_NEWNET
+	}
+
+	if limits := formatLimits(sb); limits != "" && cmd.Err == nil {
+		// the shim is started in place of the runner; the command is restored afterwards
+		// so it can be restarted as given
+		path, env := cmd.Path, cmd.Env
+		defer func() { cmd.Path, cmd.Env = path, env }()
+
+		if env == nil {
+			env = os.Environ()
+		}
+
+		cmd.Path = selfExecutable
+		cmd.Env = append(slices.Clip(env), sandboxExecEnv+"="+path, sandboxLimitsEnv+"="+limits)
+	}
+
+	errc := make(chan error, 1)
+
+	go func() {
+		// Landlock restricts the calling thread and the processes it starts. The thread is
+		// never unlocked, so it exits with this goroutine instead of being reused.
+		runtime.LockOSThread()
+
+		if err := restrictFilesystem(sb.Writable); err != nil {
+			landlockWarnOnce.Do(func() {
+				slog.Warn("filesystem sandbox unavailable; runners can write anywhere", slog.Any("error", err))
+			})
+		}
+
+		errc <- cmd.Start()
+	}()
+
+	return <-errc
+}
+
+// formatLimits encodes the resource limits of the sandbox for the shim, or returns an empty
+// string if there are none.
+func formatLimits(sb Sandbox) string {
+	if sb.CPUTime <= 0 && sb.Memory == 0 && sb.OpenFiles == 0 {
+		return ""
+	}
+
+	return fmt.Sprintf("%d %d %d", uint64(math.Ceil(sb.CPUTime.Seconds())), sb.Memory, sb.OpenFiles)
+}
+
+// execWithLimits applies the encoded resource limits and replaces the shim with the runner
+// process at path. It never returns; if the runner can't be started, the shim exits with
+// the reason on stderr.
+func execWithLimits(path, limits string) {
+	err := setLimits(limits)
+	if err == nil {
+		env := slices.DeleteFunc(os.Environ(), func(kv string) bool {
+			return strings.HasPrefix(kv, sandboxExecEnv+"=") || strings.HasPrefix(kv, sandboxLimitsEnv+"=")
+		})
+
+		err = syscall.Exec(path, os.Args, env)
+	}
+
+	fmt.Fprintf(os.Stderr, "elf sandbox: %v\n", err)
+	os.Exit(shimExitCode)
+}
+
+// shimExitCode is the exit code of a shim that could not start the runner process.
+const shimExitCode = 126
+
+// namespacesAvailable reports whether unprivileged user and network namespaces can be created.
+func namespacesAvailable() bool {
+	namespaceOnce.Do(func() {
+		truePath, err := exec.LookPath("true")
+		if err == nil {
+			probe := exec.Command(truePath)
+			probe.SysProcAttr = &syscall.SysProcAttr{Cloneflags: syscall.CLONE_NEWUSER | syscall.CLONE_NEWNET}
+
+			err = probe.Run()
+		}
+
+		namespaceAvailable = err == nil
+
+		if !namespaceAvailable {
+			slog.Warn("network sandbox unavailable; runners keep network access", slog.Any("error", err))
+		}
+	})
+
+	return namespaceAvailable
+}
+
+// restrictFilesystem makes everything except the writable directories read-only for the
+// calling thread and its children. It must be called on a locked thread.
+func restrictFilesystem(writable []string) error {
+	abi, _, errno := unix.Syscall(unix.SYS_LANDLOCK_CREATE_RULESET, 0, 0, unix.LANDLOCK_CREATE_RULESET_VERSION)
+	if errno != 0 {
+		return fmt.Errorf("landlock: %w", errno)
+	}
+
+	var handled uint64
+	for v := 1; v <= int(abi) && v < len(landlockWriteAccess); v++ {
+		handled |= landlockWriteAccess[v]
+	}
+
+	attr := unix.LandlockRulesetAttr{Access_fs: handled}
+
+	fd, _, errno := unix.Syscall(unix.SYS_LANDLOCK_CREATE_RULESET,
+		uintptr(unsafe.Pointer(&attr)), unsafe.Sizeof(attr), 0)
+	if errno != 0 {
+		return fmt.Errorf("creating landlock ruleset: %w", errno)
+	}
+
+	defer unix.Close(int(fd)) //nolint:errcheck // read-only use
+
+	// output written to /dev/null is discarded anyway
+	fileAccess := handled & (unix.LANDLOCK_ACCESS_FS_WRITE_FILE | unix.LANDLOCK_ACCESS_FS_TRUNCATE)
+	if err := addPathRule(int(fd), "/dev/null", fileAccess); err != nil {
+		return err
+	}
+
+	for _, dir := range writable {
+		if err := addPathRule(int(fd), dir, handled); err != nil {
+			return err
+		}
+	}
+
+	if err := unix.Prctl(unix.PR_SET_NO_NEW_PRIVS, 1, 0, 0, 0); err != nil {
+		return fmt.Errorf("setting no_new_privs: %w", err)
+	}
+
+	if _, _, errno = unix.Syscall(unix.SYS_LANDLOCK_RESTRICT_SELF, fd, 0, 0); errno != 0 {
+		return fmt.Errorf("applying landlock ruleset: %w", errno)
+	}
+
+	return nil
+}
+
+func addPathRule(rulesetFd int, path string, access uint64) error {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return err
+	}
+
+	pathFd, err := unix.Open(abs, unix.O_PATH|unix.O_CLOEXEC, 0)
+	if errors.Is(err, unix.ENOENT) {
+		slog.LogAttrs(context.TODO(), slog.LevelDebug, "skipping missing writable path",
+			slog.String("path", abs))
+
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("opening %s: %w", abs, err)
+	}
+
+	defer unix.Close(pathFd) //nolint:errcheck // read-only use
+
+	rule := unix.LandlockPathBeneathAttr{Allowed_access: access, Parent_fd: int32(pathFd)} //nolint:gosec // fds fit
+
+	if _, _, errno := unix.Syscall6(unix.SYS_LANDLOCK_ADD_RULE, uintptr(rulesetFd),
+		unix.LANDLOCK_RULE_PATH_BENEATH, uintptr(unsafe.Pointer(&rule)), 0, 0, 0); errno != 0 {
+		return fmt.Errorf("allowing writes to %s: %w", abs, errno)
+	}
+
+	return nil
+}
+
+// setLimits applies resource limits encoded by formatLimits to the calling process.
+func setLimits(limits string) error {
+	var cpuSecs, memory, openFiles uint64
+
+	if _, err := fmt.Sscan(limits, &cpuSecs, &memory, &openFiles); err != nil {
+		return fmt.Errorf("reading limits %q: %w", limits, err)
+	}
+
+	if cpuSecs > 0 {
+		// the process gets SIGXCPU at the limit and SIGKILL a second later
+		if err := syscall.Setrlimit(syscall.RLIMIT_CPU, &syscall.Rlimit{Cur: cpuSecs, Max: cpuSecs + 1}); err != nil {
+			return fmt.Errorf("cpu time: %w", err)
+		}
+	}
+
+	if memory > 0 {
+		if err := syscall.Setrlimit(syscall.RLIMIT_AS, &syscall.Rlimit{Cur: memory, Max: memory}); err != nil {
+			return fmt.Errorf("memory: %w", err)
+		}
+	}
+
+	if openFiles > 0 {
+		// syscall.Setrlimit keeps the limit from being reset when executing the runner
+		if err := syscall.Setrlimit(syscall.RLIMIT_NOFILE, &syscall.Rlimit{Cur: openFiles, Max: openFiles}); err != nil {
+			return fmt.Errorf("open files: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// limitExceeded returns the limit that stopped a runner process, or an empty string if it
+// exited for another reason.
+//
+// Only the CPU time limit stops a process with a signal. Memory and open file limits make
+// allocations and opening files fail inside the process, which then exits like it would for
+// any other error, so they can't be told apart from its exit status.
+func limitExceeded(state *os.ProcessState, sb Sandbox) string {
+	if state == nil || sb.CPUTime <= 0 {
+		return ""
+	}
+
+	ws, ok := state.Sys().(syscall.WaitStatus)
+	if !ok || !ws.Signaled() {
+		return ""
+	}
+
+	used := state.UserTime() + state.SystemTime()
+
+	// Go ignores SIGXCPU, so it is only stopped by the SIGKILL at the hard limit
+	if ws.Signal() == syscall.SIGXCPU || (ws.Signal() == syscall.SIGKILL && used >= sb.CPUTime) {
+		return LimitCPUTime
+	}
+
+	return ""
+}
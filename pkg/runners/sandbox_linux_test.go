@@ -0,0 +1,200 @@
+//go:build linux
+
+package runners
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/sys/unix"
+)
+
+// sandboxed configures the sandbox for the duration of a test.
+func sandboxed(t *testing.T, sb Sandbox) {
+	t.Helper()
+
+	sb.Enabled = true
+
+	Configure(Settings{Sandbox: sb})
+	t.Cleanup(func() { Configure(Settings{}) })
+}
+
+// waitExited waits for a started process to exit.
+func waitExited(t *testing.T, cmd *exec.Cmd) {
+	t.Helper()
+
+	require.Eventually(t, func() bool { return exited(cmd) }, 10*time.Second, 10*time.Millisecond)
+	releaseProcess(cmd)
+}
+
+func Test_startSandboxed_filesystem(t *testing.T) {
+	if _, _, errno := unix.Syscall(unix.SYS_LANDLOCK_CREATE_RULESET, 0, 0, unix.LANDLOCK_CREATE_RULESET_VERSION); errno != 0 {
+		t.Skip("landlock not available")
+	}
+
+	readOnly, writable := t.TempDir(), t.TempDir()
+
+	runnerWritable := t.TempDir()
+
+	sandboxed(t, Sandbox{Network: true, Writable: []string{writable}})
+
+	//nolint:gosec // test paths
+	cmd := exec.Command("sh", "-c",
+		`echo denied > "$0/file"; echo allowed > "$1/file"; echo allowed > "$2/file"; echo discarded > /dev/null`,
+		readOnly, writable, runnerWritable)
+
+	_, err := setupBuffers(cmd)
+	require.NoError(t, err)
+	require.NoError(t, startProcess(cmd, runnerWritable))
+
+	// still tracked, like a runner process that died during a task
+	require.Eventually(t, func() bool { return exited(cmd) }, 10*time.Second, 10*time.Millisecond)
+
+	assert.NoFileExists(t, filepath.Join(readOnly, "file"), "writes outside writable directories should fail")
+	assert.FileExists(t, filepath.Join(writable, "file"))
+	assert.FileExists(t, filepath.Join(runnerWritable, "file"), "writes to directories allowed for the runner should work")
+	assert.NotContains(t, cmd.Stderr.(interface{ String() string }).String(), "/dev/null")
+
+	// a restarted process may write to the same directories
+	require.NoError(t, os.Remove(filepath.Join(runnerWritable, "file")))
+
+	next, _, err := restartProcess(cmd)
+	require.NoError(t, err)
+
+	waitExited(t, next)
+
+	assert.FileExists(t, filepath.Join(runnerWritable, "file"))
+}
+
+func Test_startSandboxed_network(t *testing.T) {
+	if !namespacesAvailable() {
+		t.Skip("namespaces not available")
+	}
+
+	sandboxed(t, Sandbox{})
+
+	cmd := exec.Command("cat", "/proc/net/dev")
+
+	_, err := setupBuffers(cmd)
+	require.NoError(t, err)
+	require.NoError(t, startProcess(cmd))
+
+	t.Cleanup(func() { releaseProcess(cmd) })
+
+	var interfaces []string
+
+	for {
+		msg, msgErr := readMessage(cmd)
+		if msgErr != nil {
+			break // cat exited
+		}
+
+		if name, _, ok := strings.Cut(string(msg.line), ":"); ok {
+			interfaces = append(interfaces, strings.TrimSpace(name))
+		}
+	}
+
+	assert.Equal(t, []string{"lo"}, interfaces, "only loopback should exist in the private namespace")
+}
+
+func Test_runTask_cpuLimit(t *testing.T) {
+	if testing.Short() {
+		t.Skip("uses a second of cpu time")
+	}
+
+	sandboxed(t, Sandbox{CPUTime: time.Second, Network: true})
+
+	cmd := exec.Command("sh", "-c", "read -r task; while :; do :; done")
+
+	stdin, err := setupBuffers(cmd)
+	require.NoError(t, err)
+	require.NoError(t, startProcess(cmd))
+	t.Cleanup(func() { _ = stopProcess(cmd, "sh") })
+
+	got, err := runTask(&Task{TaskID: "Solve.1", Part: PartOne}, stdin, cmd)
+	require.NoError(t, err)
+
+	assert.Equal(t, &Result{
+		TaskID: "Solve.1",
+		Ok:     false,
+		Output: "cpu time limit of 1s exceeded",
+		Limit:  LimitCPUTime,
+	}, got)
+}
+
+func Test_startSandboxed_limits(t *testing.T) {
+	sandboxed(t, Sandbox{CPUTime: 90 * time.Second, Memory: 1 << 30, OpenFiles: 64, Network: true})
+
+	// the limits are read as soon as the process starts
+	cmd := exec.Command("sh", "-c", `echo "$(ulimit -t) $(ulimit -v) $(ulimit -n) $ELF_SANDBOX_EXEC"`)
+
+	_, err := setupBuffers(cmd)
+	require.NoError(t, err)
+	require.NoError(t, startProcess(cmd))
+
+	t.Cleanup(func() { releaseProcess(cmd) })
+
+	msg, err := readMessage(cmd)
+	require.NoError(t, err)
+
+	assert.Equal(t, "90 1048576 64 ", string(msg.line))
+	assert.Equal(t, "sh", filepath.Base(cmd.Path), "command should be restored after starting")
+}
+
+func Test_execWithLimits_invalid(t *testing.T) {
+	if os.Getenv(sandboxExecEnv) == "" {
+		self, err := os.Executable()
+		require.NoError(t, err)
+
+		//nolint:gosec // the test binary
+		cmd := exec.Command(self, "-test.run=^Test_execWithLimits_invalid$")
+		cmd.Env = append(os.Environ(), sandboxExecEnv+"=/bin/true", sandboxLimitsEnv+"=not limits")
+
+		out, err := cmd.CombinedOutput()
+
+		var exitErr *exec.ExitError
+		require.ErrorAs(t, err, &exitErr)
+		assert.Equal(t, shimExitCode, exitErr.ExitCode())
+		assert.Contains(t, string(out), "elf sandbox: reading limits")
+	}
+}
+
+func Test_limitExceeded(t *testing.T) {
+	run := func(script string) *os.ProcessState {
+		cmd := exec.Command("sh", "-c", script)
+		_ = cmd.Run()
+
+		return cmd.ProcessState
+	}
+
+	exitedNormally := run("exit 1")
+	killed := run("kill -KILL $$")
+	cpuSignal := run("kill -XCPU $$")
+
+	sb := Sandbox{Enabled: true, CPUTime: time.Hour, Memory: 1 << 30, OpenFiles: 64}
+
+	tests := []struct {
+		name  string
+		state *os.ProcessState
+		sb    Sandbox
+		want  string
+	}{
+		{"not exited", nil, sb, ""},
+		{"exit code", exitedNormally, sb, ""},
+		{"cpu signal", cpuSignal, sb, LimitCPUTime},
+		{"cpu signal without limit", cpuSignal, Sandbox{Enabled: true}, ""},
+		{"killed before cpu limit", killed, sb, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, limitExceeded(tt.state, tt.sb))
+		})
+	}
+}
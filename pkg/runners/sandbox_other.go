@@ -0,0 +1,30 @@
+//go:build !linux
+
+package runners
+
+import (
+	"log/slog"
+	"os"
+	"os/exec"
+	"sync"
+)
+
+var sandboxWarnOnce sync.Once
+
+// ExecSandboxShim does nothing; the sandbox shim is only used on Linux.
+func ExecSandboxShim() {}
+
+// startSandboxed starts a runner process without restrictions; the sandbox is only
+// supported on Linux.
+func startSandboxed(cmd *exec.Cmd, _ Sandbox) error {
+	sandboxWarnOnce.Do(func() {
+		slog.Warn("runner sandbox is only supported on linux; runners are not restricted")
+	})
+
+	return cmd.Start()
+}
+
+// limitExceeded always returns an empty string since no limits are applied.
+func limitExceeded(*os.ProcessState, Sandbox) string {
+	return ""
+}
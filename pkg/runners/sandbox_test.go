@@ -0,0 +1,67 @@
+package runners
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMain(m *testing.M) {
+	// sandboxed runners started by tests use the test binary as the shim
+	ExecSandboxShim()
+
+	os.Exit(m.Run())
+}
+
+func TestLimitError_Error(t *testing.T) {
+	sb := Sandbox{CPUTime: 10 * time.Second, Memory: 512 << 20, OpenFiles: 64}
+
+	tests := []struct {
+		limit string
+		want  string
+	}{
+		{LimitCPUTime, "cpu time limit of 10s exceeded"},
+		{"pids", "pids limit exceeded"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.limit, func(t *testing.T) {
+			assert.EqualError(t, &LimitError{Limit: tt.limit, Sandbox: sb}, tt.want)
+		})
+	}
+}
+
+func TestSandbox_failureLimits(t *testing.T) {
+	tests := []struct {
+		name string
+		sb   Sandbox
+		want string
+	}{
+		{"none", Sandbox{CPUTime: time.Second}, ""},
+		{"memory", Sandbox{Memory: 512 << 20}, "memory limit of 512 MiB"},
+		{"both", Sandbox{Memory: 512 << 20, OpenFiles: 64}, "memory limit of 512 MiB, open file limit of 64"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.sb.failureLimits())
+		})
+	}
+}
+
+func TestAllowWrite(t *testing.T) {
+	Configure(Settings{Sandbox: Sandbox{Writable: []string{"/configured"}}})
+	t.Cleanup(func() { Configure(Settings{}) })
+
+	g := newGolangRunner("exercise").(*golangRunner)
+	other := newGolangRunner("exercise").(*golangRunner)
+
+	AllowWrite(g, "/output")
+	AllowWrite(&variantRunner{Runner: other, name: "Go (variant)"}, "/variant-output")
+
+	assert.Equal(t, []string{"/output"}, g.writable)
+	assert.Equal(t, []string{"/variant-output"}, other.writable)
+	assert.Equal(t, []string{"/configured"}, settings.Sandbox.Writable, "settings should not change")
+}
@@ -0,0 +1,66 @@
+package runners
+
+import "path/filepath"
+
+// Settings configure the built-in runners.
+type Settings struct {
+	// CacheDir holds compiled exercises that are reused between runs. When empty, the elf
+	// directory in the user cache directory is used.
+	CacheDir string
+
+	// GoModTidy runs 'go mod tidy' before building Go exercises.
+	GoModTidy bool
+
+	// Python is the interpreter used for Python exercises without their own. It may be a
+	// command, a path to an interpreter, or a path to a virtual environment.
+	Python string
+
+	// PythonLib is the directory of helper modules added to PYTHONPATH. When empty, the lib
+	// directory three levels above the exercise is used.
+	PythonLib string
+
+	// NodeLib is the directory of helper modules set as NODE_PATH for JavaScript and
+	// TypeScript exercises. When empty, the lib directory three levels above the exercise is
+	// used.
+	NodeLib string
+
+	// Transpiler is the command that transpiles TypeScript exercises. The placeholders {outdir}
+	// and {entry} are replaced by the output directory and the entry file, e.g.
+	// "tsc --strict --outDir {outdir} {entry}". Without them, '--outdir=<dir> <entry>' is
+	// appended, as for "esbuild --bundle --platform=node". When empty, tsc is used.
+	Transpiler string
+
+	// CCompiler and CFlags compile C exercises. The compiler may include arguments, e.g.
+	// "ccache cc". When empty, cc and -O2 are used.
+	CCompiler string
+	CFlags    []string
+
+	// CXXCompiler and CXXFlags compile C++ exercises. When empty, c++ and -O2 are used.
+	CXXCompiler string
+	CXXFlags    []string
+
+	// Sandbox restricts the processes that run exercises.
+	Sandbox Sandbox
+}
+
+var settings Settings
+
+// Configure sets the configuration used by runners created afterwards.
+func Configure(s Settings) {
+	settings = s
+}
+
+// helperLibDir returns the directory of helper modules for an exercise in exerciseDir: the
+// configured directory, or the lib directory three levels above the exercise.
+func helperLibDir(exerciseDir, configured string) (string, error) {
+	if configured != "" {
+		return filepath.Abs(configured)
+	}
+
+	absDir, err := filepath.Abs(exerciseDir)
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(absDir, "../../..", "lib"), nil
+}
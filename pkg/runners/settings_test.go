@@ -0,0 +1,22 @@
+package runners
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_helperLibDir(t *testing.T) {
+	exerciseDir := filepath.Join(t.TempDir(), "exercises", "2015", "01-testDayOne")
+	configured := t.TempDir()
+
+	got, err := helperLibDir(exerciseDir, "")
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(filepath.Dir(filepath.Dir(filepath.Dir(exerciseDir))), "lib"), got)
+
+	got, err = helperLibDir(exerciseDir, configured)
+	require.NoError(t, err)
+	assert.Equal(t, configured, got)
+}
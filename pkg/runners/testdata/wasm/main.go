@@ -0,0 +1,34 @@
+// Command main is a WASI exercise used to test the wasm runner.
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+func main() {
+	input, err := io.ReadAll(os.Stdin)
+	if err != nil {
+		panic(err)
+	}
+
+	switch os.Args[1] {
+	case "1":
+		if string(input) == "loop" {
+			for {
+			}
+		}
+
+		fmt.Fprintln(os.Stderr, "debug output")
+		fmt.Println(len(input))
+	case "2":
+		fmt.Fprintln(os.Stderr, "part 2 not implemented")
+		os.Exit(1)
+	case "3":
+		if err = os.WriteFile(filepath.Join("/output", "vis.txt"), input, 0o600); err != nil {
+			panic(err)
+		}
+	}
+}
@@ -0,0 +1,172 @@
+package runners
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+)
+
+var ErrUnsupportedVariant = errors.New("unsupported variant")
+
+// Variant describes a runner for an existing language with a different toolchain, interpreter,
+// or build flags. Variants run the same implementation as their base runner and are reported
+// under their own name, so they can be benchmarked side by side.
+type Variant struct {
+	// Runner is the language key of the base runner, e.g. "go" or "py".
+	Runner string `mapstructure:"runner"`
+
+	// Name is shown in output and graphs. Defaults to the variant key.
+	Name string `mapstructure:"name"`
+
+	// Env holds extra environment variables as KEY=value, used when building and running.
+	Env []string `mapstructure:"env"`
+
+	// BuildFlags are passed to the compiler. Supported by Go, C, and C++ runners.
+	BuildFlags []string `mapstructure:"build-flags"`
+
+	// Interpreter replaces the Python interpreter. It may be a command, a path to an
+	// interpreter, or a path to a virtual environment.
+	Interpreter string `mapstructure:"interpreter"`
+}
+
+// variantConfigurer is implemented by runners that support variants. Each runner returns an
+// error for any setting it does not support.
+type variantConfigurer interface {
+	applyVariant(v Variant) error
+}
+
+// variantRunner reports a runner under the name of its variant.
+type variantRunner struct {
+	Runner
+	name string
+}
+
+func (v *variantRunner) String() string {
+	return v.name
+}
+
+// Version returns the version of the base runner.
+func (v *variantRunner) Version() string {
+	return VersionOf(v.Runner)
+}
+
+func (v *variantRunner) allowWrite(dir string) {
+	AllowWrite(v.Runner, dir)
+}
+
+// variants holds the configured variants by key.
+var variants = map[string]Variant{}
+
+// RegisterVariant adds a configured variant of a runner, replacing any earlier variant with
+// the same key. The base runner must already be available and the key must not name a
+// language.
+func RegisterVariant(key string, v Variant) error {
+	key = strings.ToLower(key)
+	v.Runner = strings.ToLower(v.Runner)
+
+	if _, ok := builtins[key]; ok {
+		return fmt.Errorf("%w: %s", ErrBuiltinRunner, key)
+	}
+
+	if _, ok := definitions[key]; ok {
+		return fmt.Errorf("%w: %s: variant key names a configured runner", ErrInvalidDefinition, key)
+	}
+
+	base, ok := Available[v.Runner]
+	if !ok || v.Runner == key {
+		return fmt.Errorf("%w: %s: unknown runner %q", ErrInvalidDefinition, key, v.Runner)
+	}
+
+	if _, isVariant := variants[v.Runner]; isVariant {
+		return fmt.Errorf("%w: %s: runner %q is a variant", ErrInvalidDefinition, key, v.Runner)
+	}
+
+	for _, e := range v.Env {
+		if k, _, found := strings.Cut(e, "="); !found || k == "" {
+			return fmt.Errorf("%w: %s: env %q is not KEY=value", ErrInvalidDefinition, key, e)
+		}
+	}
+
+	if v.Name == "" {
+		v.Name = key
+	}
+
+	// check the settings are supported before any runner is created
+	if err := applyVariant(base(""), v); err != nil {
+		return fmt.Errorf("%s: %w", key, err)
+	}
+
+	variants[key] = v
+	Available[key] = func(dir string) Runner {
+		r := base(dir)
+		_ = applyVariant(r, v) // checked when registered
+
+		return &variantRunner{Runner: r, name: v.Name}
+	}
+
+	return nil
+}
+
+func applyVariant(r Runner, v Variant) error {
+	c, ok := r.(variantConfigurer)
+	if !ok {
+		return fmt.Errorf("%w: %s runner has no variants", ErrUnsupportedVariant, r)
+	}
+
+	return c.applyVariant(v)
+}
+
+// LookupVariant returns the configured variant with the given key.
+func LookupVariant(key string) (Variant, bool) {
+	v, ok := variants[strings.ToLower(key)]
+	return v, ok
+}
+
+// VariantsOf returns the keys of the variants of a runner, in sorted order.
+func VariantsOf(lang string) []string {
+	lang = strings.ToLower(lang)
+
+	var keys []string
+
+	for key, v := range variants {
+		if v.Runner == lang {
+			keys = append(keys, key)
+		}
+	}
+
+	slices.Sort(keys)
+
+	return keys
+}
+
+// withEnv returns the environment of this process with extra variables added, or nil when
+// there are none so commands inherit the environment as is.
+func withEnv(extra ...[]string) []string {
+	env := slices.Concat(extra...)
+	if len(env) == 0 {
+		return nil
+	}
+
+	return append(os.Environ(), env...)
+}
+
+// unsupported returns an error naming the variant settings a runner ignores.
+func (v Variant) unsupported(runner string, buildFlags, interpreter bool) error {
+	var names []string
+
+	if !buildFlags && len(v.BuildFlags) != 0 {
+		names = append(names, "build-flags")
+	}
+
+	if !interpreter && v.Interpreter != "" {
+		names = append(names, "interpreter")
+	}
+
+	if len(names) == 0 {
+		return nil
+	}
+
+	return fmt.Errorf("%w: %s runner does not support %s", ErrUnsupportedVariant, runner, strings.Join(names, ", "))
+}
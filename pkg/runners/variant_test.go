@@ -0,0 +1,161 @@
+package runners
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func unregisterVariant(key string) {
+	delete(variants, key)
+	delete(Available, key)
+}
+
+func TestRegisterVariant(t *testing.T) {
+	tests := []struct {
+		name      string
+		key       string
+		variant   Variant
+		want      Variant
+		assertion require.ErrorAssertionFunc
+		err       error
+	}{
+		{
+			name:      "go build flags",
+			key:       "Go-NoBounds",
+			variant:   Variant{Runner: "Go", Name: "Go -B", BuildFlags: []string{"-gcflags=-B"}},
+			want:      Variant{Runner: "go", Name: "Go -B", BuildFlags: []string{"-gcflags=-B"}},
+			assertion: require.NoError,
+		},
+		{
+			name:      "default name",
+			key:       "go122",
+			variant:   Variant{Runner: "go", Env: []string{"GOTOOLCHAIN=go1.22.0"}},
+			want:      Variant{Runner: "go", Name: "go122", Env: []string{"GOTOOLCHAIN=go1.22.0"}},
+			assertion: require.NoError,
+		},
+		{
+			name:      "python interpreter",
+			key:       "pypy",
+			variant:   Variant{Runner: "py", Name: "PyPy", Interpreter: "pypy3"},
+			want:      Variant{Runner: "py", Name: "PyPy", Interpreter: "pypy3"},
+			assertion: require.NoError,
+		},
+		{
+			name:      "built-in key",
+			key:       "py",
+			variant:   Variant{Runner: "go"},
+			assertion: require.Error,
+			err:       ErrBuiltinRunner,
+		},
+		{
+			name:      "unknown runner",
+			key:       "kt-fast",
+			variant:   Variant{Runner: "kt"},
+			assertion: require.Error,
+			err:       ErrInvalidDefinition,
+		},
+		{
+			name:      "invalid env",
+			key:       "go-env",
+			variant:   Variant{Runner: "go", Env: []string{"GOTOOLCHAIN"}},
+			assertion: require.Error,
+			err:       ErrInvalidDefinition,
+		},
+		{
+			name:      "unsupported setting",
+			key:       "py-flags",
+			variant:   Variant{Runner: "py", BuildFlags: []string{"-O"}},
+			assertion: require.Error,
+			err:       ErrUnsupportedVariant,
+		},
+		{
+			name:      "runner without variants",
+			key:       "wasm-fast",
+			variant:   Variant{Runner: "wasm"},
+			assertion: require.Error,
+			err:       ErrUnsupportedVariant,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := RegisterVariant(tt.key, tt.variant)
+
+			tt.assertion(t, err)
+
+			if tt.err != nil {
+				require.ErrorIs(t, err, tt.err)
+				return
+			}
+
+			key := strings.ToLower(tt.key)
+			t.Cleanup(func() { unregisterVariant(key) })
+
+			got, ok := LookupVariant(tt.key)
+			require.True(t, ok)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.want.Name, Available[key]("").String())
+		})
+	}
+}
+
+func TestRegisterVariant_ofVariant(t *testing.T) {
+	require.NoError(t, RegisterVariant("go-pgo", Variant{Runner: "go", BuildFlags: []string{"-pgo=default.pgo"}}))
+	t.Cleanup(func() { unregisterVariant("go-pgo") })
+
+	err := RegisterVariant("go-pgo-nobounds", Variant{Runner: "go-pgo"})
+	require.ErrorIs(t, err, ErrInvalidDefinition)
+}
+
+func TestVariantsOf(t *testing.T) {
+	require.NoError(t, RegisterVariant("go-nobounds", Variant{Runner: "go", BuildFlags: []string{"-gcflags=-B"}}))
+	require.NoError(t, RegisterVariant("go-122", Variant{Runner: "go", Env: []string{"GOTOOLCHAIN=go1.22.0"}}))
+	require.NoError(t, RegisterVariant("pypy", Variant{Runner: "py", Interpreter: "pypy3"}))
+
+	t.Cleanup(func() {
+		unregisterVariant("go-nobounds")
+		unregisterVariant("go-122")
+		unregisterVariant("pypy")
+	})
+
+	assert.Equal(t, []string{"go-122", "go-nobounds"}, VariantsOf("Go"))
+	assert.Equal(t, []string{"pypy"}, VariantsOf("py"))
+	assert.Empty(t, VariantsOf("js"))
+}
+
+func Test_variantRunner(t *testing.T) {
+	require.NoError(t, RegisterVariant("c-native", Variant{
+		Runner:     "c",
+		Name:       "C native",
+		Env:        []string{"CC=gcc"},
+		BuildFlags: []string{"-march=native"},
+	}))
+	t.Cleanup(func() { unregisterVariant("c-native") })
+
+	r := Available["c-native"]("exercise")
+
+	require.IsType(t, &variantRunner{}, r)
+	assert.Equal(t, "C native", r.String())
+
+	n, ok := r.(*variantRunner).Runner.(*nativeRunner)
+	require.True(t, ok)
+	assert.Equal(t, "exercise", n.dir)
+	assert.Equal(t, []string{"CC=gcc"}, n.env)
+	assert.Equal(t, []string{"-march=native"}, n.buildFlags)
+}
+
+func Test_withEnv(t *testing.T) {
+	assert.Nil(t, withEnv(), "no extra variables should inherit the environment")
+	assert.Nil(t, withEnv(nil, []string{}))
+
+	t.Setenv("ELF_TEST_ENV", "from environment")
+
+	got := withEnv([]string{"ELF_TEST_ENV=first"}, []string{"ELF_TEST_ENV=second"})
+
+	assert.Subset(t, got, os.Environ())
+	assert.Equal(t, "ELF_TEST_ENV=second", got[len(got)-1], "later variables should take precedence")
+}
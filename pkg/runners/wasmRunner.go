@@ -0,0 +1,206 @@
+package runners
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tetratelabs/wazero"
+	"github.com/tetratelabs/wazero/imports/wasi_snapshot_preview1"
+	"github.com/tetratelabs/wazero/sys"
+)
+
+const (
+	wasmRunnerName     string = "WebAssembly"
+	wasmModuleFilename string = "exercise.wasm"
+	wasmOutputMount    string = "/output"
+)
+
+// wasmRunner runs exercises compiled to WASI modules in an embedded runtime.
+//
+// Each task runs in a new instance of the module, so no state is shared between tasks. The
+// task input is given on stdin and the part number as the only argument. The answer is read
+// from stdout, and a non-zero exit code marks the task as failed with stderr as the error.
+// Visualizations may write to /output.
+//
+// Modules have no access to the host filesystem, clock, or random source, which makes runs
+// deterministic.
+//
+// Modules run in the elf process, so the sandbox CPU time limit can't be left to the kernel.
+// A module runs on a single thread, so the time spent running tasks is counted against the
+// limit instead, and a task that runs out is stopped.
+type wasmRunner struct {
+	dir        string
+	modulePath string
+	runtime    wazero.Runtime
+	module     wazero.CompiledModule
+
+	// ctx is cancelled when the runner is stopped, which closes any running module
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	// used is the time spent running tasks, counted against the sandbox CPU time limit
+	used time.Duration
+}
+
+func newWasmRunner(dir string) Runner {
+	return &wasmRunner{
+		dir:        dir,
+		modulePath: filepath.Join(dir, "wasm", wasmModuleFilename),
+	}
+}
+
+// Start compiles the exercise module.
+func (w *wasmRunner) Start() error {
+	w.ctx, w.cancel = context.WithCancel(context.Background())
+	ctx := w.ctx
+
+	bin, err := os.ReadFile(w.modulePath)
+	if err != nil {
+		return fmt.Errorf("reading wasm module: %w", err)
+	}
+
+	// modules are closed when the context of their task is done, so a runaway module can be
+	// stopped
+	w.runtime = wazero.NewRuntimeWithConfig(ctx, wazero.NewRuntimeConfig().WithCloseOnContextDone(true))
+
+	if _, err = wasi_snapshot_preview1.Instantiate(ctx, w.runtime); err != nil {
+		return fmt.Errorf("instantiating wasi: %w", err)
+	}
+
+	w.module, err = w.runtime.CompileModule(ctx, bin)
+	if err != nil {
+		return fmt.Errorf("compiling wasm module: %w", err)
+	}
+
+	return nil
+}
+
+// Stop closes the runtime and every module compiled in it.
+func (w *wasmRunner) Stop() error {
+	if w.runtime == nil {
+		return nil
+	}
+
+	w.cancel()
+
+	if err := w.runtime.Close(context.Background()); err != nil {
+		return fmt.Errorf("failed to close wasm runtime: %w", err)
+	}
+
+	return nil
+}
+
+// Cleanup does nothing; the wasm runner does not write to the exercise directory.
+func (w *wasmRunner) Cleanup() error {
+	return nil
+}
+
+func (w *wasmRunner) Run(task *Task) (*Result, error) {
+	if w.module == nil {
+		return nil, errors.New("wasm runner not started")
+	}
+
+	ctx, cancel := w.taskContext()
+	defer cancel()
+
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return cpuTimeExceeded(task), nil
+	}
+
+	stdout, stderr := new(bytes.Buffer), new(bytes.Buffer)
+
+	cfg := wazero.NewModuleConfig().
+		WithName(""). // anonymous, so each task gets a new instance
+		WithArgs(wasmModuleFilename, strconv.Itoa(int(task.Part))).
+		WithStdin(strings.NewReader(task.Input)).
+		WithStdout(stdout).
+		WithStderr(stderr).
+		WithStartFunctions() // started below so only the exercise is timed
+
+	if task.Part == Visualize && task.OutputDir != "" {
+		cfg = cfg.WithFSConfig(wazero.NewFSConfig().WithDirMount(task.OutputDir, wasmOutputMount))
+	}
+
+	mod, err := w.runtime.InstantiateModule(ctx, w.module, cfg)
+	if err != nil {
+		return nil, fmt.Errorf("instantiating wasm module: %w", err)
+	}
+
+	defer mod.Close(ctx) //nolint:errcheck // module is discarded
+
+	start := mod.ExportedFunction("_start")
+	if start == nil {
+		return nil, errors.New("wasm module has no _start function")
+	}
+
+	begin := time.Now()
+	_, err = start.Call(ctx)
+	elapsed := time.Since(begin)
+	duration := elapsed.Seconds()
+
+	w.used += elapsed
+
+	var exitErr *sys.ExitError
+	if errors.As(err, &exitErr) {
+		switch exitErr.ExitCode() {
+		case 0:
+			err = nil
+
+		case sys.ExitCodeDeadlineExceeded:
+			return cpuTimeExceeded(task), nil
+		}
+	}
+
+	r := &Result{
+		TaskID:   task.TaskID,
+		Ok:       err == nil,
+		Duration: duration,
+	}
+
+	if r.Ok {
+		r.Output = strings.TrimSpace(stdout.String())
+
+		// anything written to stderr by a successful task is considered a debug message
+		scanner := bufio.NewScanner(stderr)
+		for scanner.Scan() {
+			printDebug(scanner.Bytes())
+		}
+	} else {
+		r.Output = strings.TrimSpace(stderr.String())
+		if r.Output == "" {
+			r.Output = err.Error()
+		}
+	}
+
+	return r, nil
+}
+
+// taskContext returns the context a task runs in. With the sandbox enabled, it ends when the
+// CPU time left to the runner has been used.
+func (w *wasmRunner) taskContext() (context.Context, context.CancelFunc) {
+	sb := settings.Sandbox
+	if !sb.Enabled || sb.CPUTime <= 0 {
+		return context.WithCancel(w.ctx)
+	}
+
+	return context.WithTimeout(w.ctx, sb.CPUTime-w.used)
+}
+
+func cpuTimeExceeded(task *Task) *Result {
+	limitErr := &LimitError{Limit: LimitCPUTime, Sandbox: settings.Sandbox}
+
+	return &Result{TaskID: task.TaskID, Ok: false, Output: limitErr.Error(), Limit: LimitCPUTime}
+}
+
+// String returns a string representation of the runner type.
+func (w *wasmRunner) String() string {
+	return wasmRunnerName
+}
@@ -0,0 +1,128 @@
+package runners
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_newWasmRunner(t *testing.T) {
+	dir := filepath.Join("testdata", "2015", "01-testDayOne")
+
+	assert.Equal(t, &wasmRunner{
+		dir:        dir,
+		modulePath: filepath.Join(dir, "wasm", "exercise.wasm"),
+	}, newWasmRunner(dir))
+}
+
+// buildWasmExercise compiles the test exercise to a WASI module in a new exercise directory.
+func buildWasmExercise(t *testing.T) string {
+	t.Helper()
+
+	exDir := t.TempDir()
+
+	cmd := exec.Command("go", "build", "-o", filepath.Join(exDir, "wasm", "exercise.wasm"), "./testdata/wasm")
+	cmd.Env = append(os.Environ(), "GOOS=wasip1", "GOARCH=wasm")
+
+	out, err := cmd.CombinedOutput()
+	require.NoError(t, err, string(out))
+
+	return exDir
+}
+
+func Test_wasmRunner_Run(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping wasm build in short mode")
+	}
+
+	exDir := buildWasmExercise(t)
+	outDir := t.TempDir()
+
+	w := newWasmRunner(exDir)
+	require.NoError(t, w.Start())
+
+	t.Cleanup(func() { require.NoError(t, w.Stop()) })
+
+	tests := []struct {
+		name   string
+		task   *Task
+		wantOk bool
+		want   string
+	}{
+		{
+			name:   "part one",
+			task:   &Task{TaskID: "Solve.1", Part: PartOne, Input: "abcde"},
+			wantOk: true,
+			want:   "5",
+		},
+		{
+			name:   "part two fails",
+			task:   &Task{TaskID: "Solve.2", Part: PartTwo, Input: "abcde"},
+			wantOk: false,
+			want:   "part 2 not implemented",
+		},
+		{
+			name:   "visualize",
+			task:   &Task{TaskID: "Vis", Part: Visualize, Input: "abcde", OutputDir: outDir},
+			wantOk: true,
+			want:   "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := w.Run(tt.task)
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.task.TaskID, got.TaskID)
+			assert.Equal(t, tt.wantOk, got.Ok)
+			assert.Equal(t, tt.want, got.Output)
+			assert.Positive(t, got.Duration)
+		})
+	}
+
+	assert.FileExists(t, filepath.Join(outDir, "vis.txt"))
+}
+
+func Test_wasmRunner_Run_cpuTimeLimit(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping wasm build in short mode")
+	}
+
+	Configure(Settings{Sandbox: Sandbox{Enabled: true, CPUTime: 200 * time.Millisecond}})
+	t.Cleanup(func() { Configure(Settings{}) })
+
+	w := newWasmRunner(buildWasmExercise(t))
+	require.NoError(t, w.Start())
+
+	t.Cleanup(func() { require.NoError(t, w.Stop()) })
+
+	want := &Result{TaskID: "Solve.1", Ok: false, Output: "cpu time limit of 200ms exceeded", Limit: LimitCPUTime}
+
+	got, err := w.Run(&Task{TaskID: "Solve.1", Part: PartOne, Input: "loop"})
+	require.NoError(t, err)
+	assert.Equal(t, want, got)
+
+	// the allowance covers every task of the runner
+	got, err = w.Run(&Task{TaskID: "Solve.1", Part: PartOne, Input: "abcde"})
+	require.NoError(t, err)
+	assert.Equal(t, want, got)
+}
+
+func Test_wasmRunner_Start(t *testing.T) {
+	w := newWasmRunner(t.TempDir())
+
+	require.ErrorIs(t, w.Start(), os.ErrNotExist)
+	require.NoError(t, w.Stop())
+}
+
+func Test_wasmRunner_String(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "WebAssembly", newWasmRunner("").String())
+}
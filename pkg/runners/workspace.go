@@ -0,0 +1,216 @@
+package runners
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+)
+
+// workspacePrefix starts the names of the temporary directories runners stage wrappers and
+// builds in. It is followed by the id of the owning process.
+const workspacePrefix = "elf-runner-"
+
+// artifactNames are files and directories written into exercise directories by earlier
+// versions of the runners.
+var artifactNames = []string{
+	golangWrapperFilename,
+	pythonWrapperFilename,
+	nodeWrapperFilename,
+	nodeBuildDirname,
+	cLanguage.wrapperFilename,
+	cppLanguage.wrapperFilename,
+	nativeWrapperExecutableFilename,
+	nativeWrapperExecutableFilename + ".exe",
+}
+
+var (
+	// yearDirPattern and dayDirPattern match the directories exercises are downloaded to,
+	// e.g. 2015/01-notQuiteLisp.
+	yearDirPattern = regexp.MustCompile(`^\d{4}$`)
+	dayDirPattern  = regexp.MustCompile(`^\d{2}-`)
+)
+
+var (
+	workspaceMux  sync.Mutex
+	workspaces    = map[string]struct{}{}
+	interruptOnce sync.Once
+)
+
+// newWorkspace creates a temporary directory for a runner. Workspaces that have not been
+// removed are deleted if elf is interrupted.
+func newWorkspace() (string, error) {
+	dir, err := os.MkdirTemp("", workspacePrefix+strconv.Itoa(os.Getpid())+"-*")
+	if err != nil {
+		return "", err
+	}
+
+	interruptOnce.Do(removeWorkspacesOnInterrupt)
+
+	workspaceMux.Lock()
+	workspaces[dir] = struct{}{}
+	workspaceMux.Unlock()
+
+	return dir, nil
+}
+
+// removeWorkspace deletes a workspace and everything in it.
+func removeWorkspace(dir string) error {
+	if dir == "" {
+		return nil
+	}
+
+	workspaceMux.Lock()
+	delete(workspaces, dir)
+	workspaceMux.Unlock()
+
+	return os.RemoveAll(dir)
+}
+
+func removeWorkspacesOnInterrupt() {
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, os.Interrupt, syscall.SIGTERM)
+
+	go func() {
+		sig := <-ch
+
+		killProcesses()
+
+		workspaceMux.Lock()
+		for dir := range workspaces {
+			_ = os.RemoveAll(dir)
+		}
+		workspaceMux.Unlock()
+
+		// exit with the conventional status for a process killed by the signal
+		code := 1
+		if s, ok := sig.(syscall.Signal); ok {
+			code = 128 + int(s)
+		}
+
+		os.Exit(code)
+	}()
+}
+
+// FindArtifacts returns wrapper files and build output left in the exercise tree at root,
+// followed by runner workspaces in the temporary directory whose process has exited. Only
+// entries directly in an exercise directory are artifacts, so files elsewhere that happen to
+// share a name are kept.
+func FindArtifacts(root string) ([]string, error) {
+	names := slices.Clone(artifactNames)
+
+	for _, def := range definitions {
+		if def.Wrapper != "" {
+			names = append(names, OutputFilename(def.Wrapper))
+		}
+	}
+
+	var found []string
+
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if path == root || !slices.Contains(names, d.Name()) || !inExerciseDir(path) {
+			return nil
+		}
+
+		found = append(found, path)
+
+		if d.IsDir() {
+			return filepath.SkipDir
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	all, err := filepath.Glob(filepath.Join(os.TempDir(), workspacePrefix+"*"))
+	if err != nil {
+		return nil, err
+	}
+
+	for _, dir := range all {
+		pid, _, _ := strings.Cut(strings.TrimPrefix(filepath.Base(dir), workspacePrefix), "-")
+
+		if n, convErr := strconv.Atoi(pid); convErr != nil || !processExists(n) {
+			found = append(found, dir)
+		}
+	}
+
+	return found, nil
+}
+
+// inExerciseDir reports whether path is directly in an exercise directory.
+func inExerciseDir(path string) bool {
+	day := filepath.Dir(path)
+	year := filepath.Dir(day)
+
+	return dayDirPattern.MatchString(filepath.Base(day)) && yearDirPattern.MatchString(filepath.Base(year))
+}
+
+// processExists reports whether a process is running with the given id.
+func processExists(pid int) bool {
+	if pid == os.Getpid() {
+		return true
+	}
+
+	p, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+
+	// signal 0 checks for the process without affecting it
+	err = p.Signal(syscall.Signal(0))
+
+	return err == nil || errors.Is(err, syscall.EPERM)
+}
+
+// RemoveArtifacts deletes the given artifacts, continuing past failures.
+func RemoveArtifacts(paths []string) error {
+	var errs []error
+
+	for _, p := range paths {
+		if err := os.RemoveAll(p); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// CacheDir returns the directory cached executables are kept in. It may not exist yet.
+func CacheDir() (string, error) {
+	base := settings.CacheDir
+	if base == "" {
+		userCache, err := os.UserCacheDir()
+		if err != nil {
+			return "", fmt.Errorf("finding cache directory: %w", err)
+		}
+
+		base = filepath.Join(userCache, "elf")
+	}
+
+	return filepath.Join(base, "go"), nil
+}
+
+// ClearCache removes all cached executables.
+func ClearCache() error {
+	dir, err := CacheDir()
+	if err != nil {
+		return err
+	}
+
+	return os.RemoveAll(dir)
+}
@@ -0,0 +1,79 @@
+package runners
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_newWorkspace(t *testing.T) {
+	dir, err := newWorkspace()
+	require.NoError(t, err)
+	assert.DirExists(t, dir)
+
+	workspaceMux.Lock()
+	_, tracked := workspaces[dir]
+	workspaceMux.Unlock()
+	assert.True(t, tracked)
+
+	require.NoError(t, removeWorkspace(dir))
+	assert.NoDirExists(t, dir)
+
+	workspaceMux.Lock()
+	_, tracked = workspaces[dir]
+	workspaceMux.Unlock()
+	assert.False(t, tracked)
+}
+
+func TestFindArtifacts(t *testing.T) {
+	t.Setenv("TMPDIR", t.TempDir())
+
+	root := t.TempDir()
+	exDir := filepath.Join(root, "2015", "01-testDayOne")
+
+	for _, fp := range []string{
+		filepath.Join(exDir, "go", "exercise.go"),
+		filepath.Join(exDir, "runtime-wrapper.go"),
+		filepath.Join(exDir, "runtime-wrapper"),
+		filepath.Join(exDir, "runtime-build", "index.js"),
+		filepath.Join(exDir, "input.txt"),
+		filepath.Join(exDir, "go", "runtime-wrapper.go"),
+		filepath.Join(root, "notes", "runtime-wrapper"),
+		filepath.Join(root, "2015", "runtime-wrapper.py"),
+	} {
+		require.NoError(t, os.MkdirAll(filepath.Dir(fp), 0o750))
+		require.NoError(t, os.WriteFile(fp, nil, 0o600))
+	}
+
+	active, err := newWorkspace()
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = removeWorkspace(active) })
+
+	// no process has an id this large
+	stale := filepath.Join(os.TempDir(), workspacePrefix+"2147483646-123")
+	require.NoError(t, os.MkdirAll(stale, 0o750))
+
+	got, err := FindArtifacts(root)
+	require.NoError(t, err)
+
+	assert.ElementsMatch(t, []string{
+		filepath.Join(exDir, "runtime-build"),
+		filepath.Join(exDir, "runtime-wrapper"),
+		filepath.Join(exDir, "runtime-wrapper.go"),
+		stale,
+	}, got)
+
+	require.NoError(t, RemoveArtifacts(got))
+
+	for _, fp := range got {
+		assert.NoFileExists(t, fp)
+	}
+
+	assert.FileExists(t, filepath.Join(exDir, "go", "exercise.go"))
+	assert.FileExists(t, filepath.Join(exDir, "go", "runtime-wrapper.go"))
+	assert.FileExists(t, filepath.Join(root, "notes", "runtime-wrapper"))
+	assert.DirExists(t, active)
+}
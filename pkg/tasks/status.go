@@ -21,5 +21,9 @@ type Result struct {
 	Status   TaskStatus
 	Output   string
 	Expected string
+	Trace    string
 	Duration float64
+
+	// ParseDuration is the time spent parsing the input, for exercises with a parse step.
+	ParseDuration float64
 }
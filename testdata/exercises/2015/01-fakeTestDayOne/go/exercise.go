@@ -7,6 +7,7 @@ package exercises
 import (
 	"fmt"
 	"os"
+	"path/filepath"
 	"strings"
 
 	"github.com/asphaltbuffet/elf/internal/common"
@@ -33,8 +34,8 @@ func (e Exercise) Two(in string) (any, error) {
 	return strings.ToUpper(in), nil
 }
 
-// Visualize returns the visualization of the exercise.
-func (e Exercise) Vis(in string, out string) error {
+// Vis writes the visualization of the exercise to outputDir.
+func (e Exercise) Vis(in string, outputDir string) error {
 	// the test exercise writes the input as-is
-	return os.WriteFile(out, []byte(in), 0o600)
+	return os.WriteFile(filepath.Join(outputDir, "input.txt"), []byte(in), 0o600)
 }